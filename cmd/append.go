@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// appendFormats are the -o formats --output-append can add to
+var appendFormats = map[string]bool{
+	"json":   true,
+	"ndjson": true,
+	"csv":    true,
+	"text":   true,
+}
+
+// appendResults adds this scan to an existing file instead of replacing it
+// A file that doesn't exist yet is created, as if --output-append wasn't given
+func appendResults(programs []Program, filename, format string, opts outputOptions) error {
+	// Step 1: Read what's there already
+	existing, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read existing file: %v", err)
+	}
+
+	// Step 2: Write the old content plus the new scan
+	switch format {
+	case "json":
+		return appendToJSON(programs, filename, existing, opts)
+	case "csv":
+		separator := fmt.Sprintf("\n# %s\n", scanSeparator())
+		return appendToFile(filename, existing, separator, func(w io.Writer) error {
+			return writeCSV(w, programs)
+		})
+	case "ndjson":
+		// One object per line needs no separator: the new lines simply follow
+		return appendToFile(filename, existing, "", func(w io.Writer) error {
+			return writeNDJSON(w, programs)
+		})
+	case "text":
+		separator := fmt.Sprintf("\n%s\n%s\n%s\n\n", strings.Repeat("#", 50), scanSeparator(), strings.Repeat("#", 50))
+		return appendToFile(filename, existing, separator, func(w io.Writer) error {
+			writeText(w, programs, opts)
+			return nil
+		})
+	}
+	return fmt.Errorf("--output-append doesn't support %s", outputFormats[format])
+}
+
+// scanSeparator names the scan that follows in an appended CSV or text file
+func scanSeparator() string {
+	hostname, _ := os.Hostname() // Leave it out if the name can't be read
+	return strings.TrimSpace(fmt.Sprintf("WinClone scan of %s on %s", hostname, time.Now().Format("2006-01-02 15:04:05")))
+}
+
+// appendToFile rewrites a file as its old content, the separator and then the new section
+// The separator is only written when there was something before it
+func appendToFile(filename string, existing []byte, separator string, write func(io.Writer) error) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	if len(existing) > 0 {
+		_, err = file.Write(existing)
+		if err == nil {
+			_, err = io.WriteString(file, separator)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write file: %v", err)
+		}
+	}
+	err = write(file)
+	if err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// appendToJSON rewrites a JSON file as an array of wrapped scans, with this scan last
+// An existing single scan becomes the first element; earlier elements are copied
+// as they are, so fields from a newer WinClone aren't lost
+func appendToJSON(programs []Program, filename string, existing []byte, opts outputOptions) error {
+	var scans []json.RawMessage
+	trimmed := bytes.TrimSpace(existing)
+	switch {
+	case len(trimmed) == 0:
+		// A new (or empty) file: this scan is the first element
+	case trimmed[0] == '[':
+		err := json.Unmarshal(trimmed, &scans)
+		if err != nil {
+			return fmt.Errorf("failed to parse existing JSON: %v", err)
+		}
+	default:
+		scans = append(scans, json.RawMessage(trimmed))
+	}
+
+	// Each element must be a wrapped scan; a bare program list (--wrap=false)
+	// would turn into a mix of programs and scans
+	for _, scan := range scans {
+		var envelope struct {
+			Programs json.RawMessage `json:"programs"`
+		}
+		err := json.Unmarshal(scan, &envelope)
+		if err != nil || envelope.Programs == nil {
+			return fmt.Errorf("%s doesn't hold wrapped scans, so it can't be appended to (was it saved with --wrap=false?)", filename)
+		}
+	}
+
+	// Appended scans are always wrapped, so each one says where and when it was taken
+	scan, err := json.Marshal(newScanResult(programs, opts.Label))
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	scans = append(scans, scan)
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err = encoder.Encode(scans)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return file.Commit()
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cacheFilePath returns where the last scan is cached between runs
+func cacheFilePath() string {
+	return filepath.Join(os.TempDir(), "winclone_cache.json")
+}
+
+// loadCachedScan returns the cached scan if it's younger than ttl and the registry
+// hasn't changed since it was taken
+// Any problem (no cache, unreadable cache, registry error) just means "scan again"
+func loadCachedScan(scanner *Scanner, ttl time.Duration) ([]Program, bool) {
+	if ttl <= 0 {
+		return nil, false
+	}
+
+	// Step 1: The cache has to exist and be recent enough
+	cached, err := loadScanFile(cacheFilePath())
+	if err != nil || cached.Timestamp.IsZero() {
+		return nil, false
+	}
+	age := time.Since(cached.Timestamp)
+	if age < 0 || age > ttl {
+		return nil, false
+	}
+
+	// Step 2: Installing or removing a program updates the Uninstall key's
+	// last-write time, so anything written after the cache makes it stale
+	lastChange, err := scanner.lastChange()
+	if err != nil || lastChange.After(cached.Timestamp) {
+		return nil, false
+	}
+
+	return cached.Programs, true
+}
+
+// saveScanCache stores a fresh scan for loadCachedScan and --changed-since-cache
+func saveScanCache(programs []Program) error {
+	return saveToJSON(programs, cacheFilePath(), outputOptions{Wrap: true})
+}
+
+// reportChangesSinceCache compares a fresh scan with the cached one, prints
+// only what changed, and then replaces the cache with the fresh scan
+// On the first run there is nothing to compare with, so it just saves a baseline
+func reportChangesSinceCache(programs []Program) error {
+	cachePath := cacheFilePath()
+
+	// Step 1: Load the previous scan (if there is one)
+	previous, err := loadScanFile(cachePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = saveScanCache(programs)
+		if err != nil {
+			return fmt.Errorf("failed to write cache: %v", err)
+		}
+		fmt.Printf("\nNo cached scan found - saved a baseline of %d programs to %s\n", len(programs), cachePath)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %v", err)
+	}
+
+	// Step 2: Print only the changes
+	diff := diffPrograms(previous.Programs, programs)
+
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("CHANGES SINCE %s\n", previous.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	if diff.isEmpty() {
+		fmt.Println("No changes.")
+	} else {
+		displayDiff(diff)
+	}
+
+	// Step 3: Replace the cache so the next run compares against this one
+	err = saveScanCache(programs)
+	if err != nil {
+		return fmt.Errorf("failed to update cache: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// programChange is a program found in both scans with a different version or path
+type programChange struct {
+	Old Program `json:"old"`
+	New Program `json:"new"`
+}
+
+// programDiff holds the differences between two program lists
+// The JSON tags are used by "winclone diff --output"
+type programDiff struct {
+	Added   []Program       `json:"added"`   // Only in the new list
+	Removed []Program       `json:"removed"` // Only in the old list
+	Changed []programChange `json:"changed"` // In both lists, but with a different version or path
+
+	Unchanged []Program `json:"-"` // In both lists with the same version and path (from the new list)
+}
+
+// isEmpty reports whether the two lists were identical
+func (d programDiff) isEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffPrograms compares two program lists by name (case-insensitive)
+// Some products register the same name more than once, so entries are matched
+// up per name: identical entries cancel out, leftovers on both sides become
+// changes, and anything still left over is added or removed
+func diffPrograms(oldPrograms, newPrograms []Program) programDiff {
+	var diff programDiff
+
+	oldByName := make(map[string][]Program)
+	for _, program := range oldPrograms {
+		key := strings.ToLower(program.Name)
+		oldByName[key] = append(oldByName[key], program)
+	}
+	newByName := make(map[string][]Program)
+	for _, program := range newPrograms {
+		key := strings.ToLower(program.Name)
+		newByName[key] = append(newByName[key], program)
+	}
+
+	// Collect every name from both sides, sorted so the output is stable
+	names := make(map[string]bool)
+	for name := range oldByName {
+		names[name] = true
+	}
+	for name := range newByName {
+		names[name] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	for _, name := range sortedNames {
+		oldLeft, newLeft, unchanged := removeUnchanged(oldByName[name], newByName[name])
+		diff.Unchanged = append(diff.Unchanged, unchanged...)
+
+		// Pair the leftovers up as changes
+		for len(oldLeft) > 0 && len(newLeft) > 0 {
+			diff.Changed = append(diff.Changed, programChange{Old: oldLeft[0], New: newLeft[0]})
+			oldLeft, newLeft = oldLeft[1:], newLeft[1:]
+		}
+
+		diff.Removed = append(diff.Removed, oldLeft...)
+		diff.Added = append(diff.Added, newLeft...)
+	}
+
+	return diff
+}
+
+// removeUnchanged drops entries whose version and install path appear on both sides
+// The dropped entries are returned too, as they appear in the new list
+func removeUnchanged(oldPrograms, newPrograms []Program) ([]Program, []Program, []Program) {
+	var oldLeft, unchanged []Program
+	remaining := append([]Program(nil), newPrograms...)
+
+	for _, oldProgram := range oldPrograms {
+		matched := false
+		for i, newProgram := range remaining {
+			if newProgram.Version == oldProgram.Version && normalizePath(newProgram.Path) == normalizePath(oldProgram.Path) {
+				unchanged = append(unchanged, newProgram)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			oldLeft = append(oldLeft, oldProgram)
+		}
+	}
+
+	return oldLeft, remaining, unchanged
+}
+
+// displayDiff prints the added, removed and changed programs
+func displayDiff(diff programDiff) {
+	writeDiff(os.Stdout, diff)
+}
+
+// writeDiff writes the added, removed and changed programs to any writer
+// This is shared by screen output and "winclone diff -o report.txt"
+func writeDiff(w io.Writer, diff programDiff) {
+	fmt.Fprintf(w, "Added: %d\n", len(diff.Added))
+	for _, program := range diff.Added {
+		fmt.Fprintf(w, "  + %s\n", programLabel(program))
+	}
+
+	fmt.Fprintf(w, "\nRemoved: %d\n", len(diff.Removed))
+	for _, program := range diff.Removed {
+		fmt.Fprintf(w, "  - %s\n", programLabel(program))
+	}
+
+	fmt.Fprintf(w, "\nChanged: %d\n", len(diff.Changed))
+	for _, change := range diff.Changed {
+		if change.Old.Version != change.New.Version {
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", change.New.Name, versionOrUnknown(change.Old.Version), versionOrUnknown(change.New.Version))
+		} else {
+			fmt.Fprintf(w, "  ~ %s: moved from %s to %s\n", change.New.Name, pathOrUnknown(change.Old.Path), pathOrUnknown(change.New.Path))
+		}
+	}
+}
+
+// versionOrUnknown returns the version, or "unknown" if it is empty
+func versionOrUnknown(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	return version
+}
+
+// pathOrUnknown returns the install path, or "unknown" if it is empty
+func pathOrUnknown(path string) string {
+	if path == "" {
+		return "unknown"
+	}
+	return path
+}
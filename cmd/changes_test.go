@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffPrograms(t *testing.T) {
+	tests := []struct {
+		name      string
+		old       []Program
+		new       []Program
+		added     []Program
+		removed   []Program
+		changed   []programChange
+		unchanged []Program
+	}{
+		{
+			name:      "identical",
+			old:       []Program{{Name: "Git", Version: "2.43.0"}},
+			new:       []Program{{Name: "Git", Version: "2.43.0"}},
+			unchanged: []Program{{Name: "Git", Version: "2.43.0"}},
+		},
+		{
+			name:    "added and removed",
+			old:     []Program{{Name: "7-Zip", Version: "23.01"}},
+			new:     []Program{{Name: "Git", Version: "2.43.0"}},
+			added:   []Program{{Name: "Git", Version: "2.43.0"}},
+			removed: []Program{{Name: "7-Zip", Version: "23.01"}},
+		},
+		{
+			name: "version changed",
+			old:  []Program{{Name: "Git", Version: "2.42.0"}},
+			new:  []Program{{Name: "Git", Version: "2.43.0"}},
+			changed: []programChange{
+				{Old: Program{Name: "Git", Version: "2.42.0"}, New: Program{Name: "Git", Version: "2.43.0"}},
+			},
+		},
+		{
+			name: "path changed",
+			old:  []Program{{Name: "Git", Version: "2.43.0", Path: `C:\Git`}},
+			new:  []Program{{Name: "Git", Version: "2.43.0", Path: `D:\Git`}},
+			changed: []programChange{
+				{Old: Program{Name: "Git", Version: "2.43.0", Path: `C:\Git`}, New: Program{Name: "Git", Version: "2.43.0", Path: `D:\Git`}},
+			},
+		},
+		{
+			name:      "names and paths compared case-insensitively",
+			old:       []Program{{Name: "git", Version: "2.43.0", Path: `C:\Program Files\Git\`}},
+			new:       []Program{{Name: "Git", Version: "2.43.0", Path: `c:\program files\git`}},
+			unchanged: []Program{{Name: "Git", Version: "2.43.0", Path: `c:\program files\git`}},
+		},
+		{
+			name: "same name registered twice",
+			old: []Program{
+				{Name: "Python Launcher", Version: "3.11.7"},
+				{Name: "Python Launcher", Version: "3.12.1"},
+			},
+			new: []Program{
+				{Name: "Python Launcher", Version: "3.12.1"},
+				{Name: "Python Launcher", Version: "3.12.2"},
+				{Name: "Python Launcher", Version: "3.13.0"},
+			},
+			added: []Program{{Name: "Python Launcher", Version: "3.13.0"}},
+			changed: []programChange{
+				{Old: Program{Name: "Python Launcher", Version: "3.11.7"}, New: Program{Name: "Python Launcher", Version: "3.12.2"}},
+			},
+			unchanged: []Program{{Name: "Python Launcher", Version: "3.12.1"}},
+		},
+		{
+			name: "sorted by name",
+			old:  []Program{{Name: "Zoom"}, {Name: "Audacity"}},
+			new:  []Program{{Name: "VLC"}, {Name: "Blender"}},
+			added: []Program{
+				{Name: "Blender"},
+				{Name: "VLC"},
+			},
+			removed: []Program{
+				{Name: "Audacity"},
+				{Name: "Zoom"},
+			},
+		},
+		{
+			name: "both empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffPrograms(tt.old, tt.new)
+			if !reflect.DeepEqual(diff.Added, tt.added) {
+				t.Errorf("Added = %+v, want %+v", diff.Added, tt.added)
+			}
+			if !reflect.DeepEqual(diff.Removed, tt.removed) {
+				t.Errorf("Removed = %+v, want %+v", diff.Removed, tt.removed)
+			}
+			if !reflect.DeepEqual(diff.Changed, tt.changed) {
+				t.Errorf("Changed = %+v, want %+v", diff.Changed, tt.changed)
+			}
+			if !reflect.DeepEqual(diff.Unchanged, tt.unchanged) {
+				t.Errorf("Unchanged = %+v, want %+v", diff.Unchanged, tt.unchanged)
+			}
+			wantEmpty := len(tt.added) == 0 && len(tt.removed) == 0 && len(tt.changed) == 0
+			if diff.isEmpty() != wantEmpty {
+				t.Errorf("isEmpty() = %v, want %v", diff.isEmpty(), wantEmpty)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare <baseline.json> [other.json]",
+	Short: "Compare this machine against a baseline, or two machines' scans",
+	Long: `With one file, scan the installed programs and compare them with a
+baseline saved earlier (with "winclone scan -o baseline.json" or "compare --update"):
+- Added: programs installed since the baseline
+- Removed: programs uninstalled since the baseline
+- Changed: programs upgraded, downgraded or moved
+
+Run it from a scheduled task to detect software drift.
+
+- --update: Overwrites the baseline with this scan after reporting, so the
+  next run only shows newer changes. If the baseline doesn't exist yet,
+  --update creates it. A baseline collected with --output-append keeps its
+  earlier scans and gets this one added at the end
+
+With two files, compare two saved scans, e.g. from an old and a new PC. The
+report names each machine (hostname and scan time) and lists:
+- Programs only on machine A
+- Programs only on machine B
+- Programs on both, with different versions
+- Programs on both with the same version (install paths may differ)
+
+- --json: Prints the two-machine report as JSON instead of text
+- -o FILE: Saves the two-machine report instead (JSON for .json, text otherwise)
+  Both are refused with a single baseline file, whose report is always text
+
+Exit codes:
+  0  Nothing changed (or both machines have the same programs)
+  1  Something was added, removed or changed
+  2  A file could not be read or written, or the scan failed
+
+Examples:
+  winclone compare baseline.json            # Report drift since the baseline
+  winclone compare baseline.json --update   # Report, then accept the changes
+  winclone compare old-pc.json new-pc.json  # What does the new PC still need?
+  winclone compare a.json b.json -o report.json`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// This function runs when the user types "winclone compare <baseline>"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		if len(args) == 2 {
+			compareMachinesCommand(cmd, args[0], args[1])
+			return
+		}
+
+		// The baseline report is always text on stdout; --json and -o belong to the two-file report
+		if cmd.Flags().Changed("json") || cmd.Flags().Changed("output") {
+			fmt.Fprintln(os.Stderr, "Error: --json and -o only work when comparing two files")
+			os.Exit(2)
+		}
+
+		baselineFile := args[0]
+		update, _ := cmd.Flags().GetBool("update")
+
+		// Step 1: Load the baseline
+		// A missing baseline is fine with --update: this scan becomes the first one
+		baseline, err := loadScanFile(baselineFile)
+		missing := errors.Is(err, fs.ErrNotExist)
+		if err != nil && !(missing && update) {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", baselineFile, err)
+			os.Exit(2)
+		}
+
+		// Step 2: Scan this machine the same way snapshots are taken
+		fmt.Fprintln(os.Stderr, "WinClone - Comparing against the baseline...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		sortPrograms(programs, "name")
+
+		// Step 3: Report the differences
+		var diff programDiff
+		if missing {
+			fmt.Printf("No baseline at %s yet - saving this scan of %d programs as the baseline\n", baselineFile, len(programs))
+		} else {
+			diff = diffPrograms(baseline.Programs, programs)
+			writeDiffReport(os.Stdout, diff, baselineFile, "this machine")
+		}
+
+		// Step 4: Accept the changes if requested
+		if update {
+			err = updateBaseline(programs, baselineFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating %s: %v\n", baselineFile, err)
+				os.Exit(2)
+			}
+			fmt.Printf("\nBaseline updated: %s\n", baselineFile)
+		}
+
+		// Step 5: Exit with 1 when something changed, so scheduled tasks can alert on it
+		if !diff.isEmpty() {
+			os.Exit(1)
+		}
+	},
+}
+
+// updateBaseline saves this scan as the new baseline for "compare --update"
+// A baseline collected with --output-append gets the scan added to its end
+// instead, so the earlier scans in it aren't thrown away
+func updateBaseline(programs []Program, filename string) error {
+	existing, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read existing file: %v", err)
+	}
+	if isAppendedScan(existing) {
+		return appendToJSON(programs, filename, existing, outputOptions{})
+	}
+	return saveToJSON(programs, filename, outputOptions{Wrap: true})
+}
+
+// compareMachinesCommand runs "winclone compare <a.json> <b.json>"
+func compareMachinesCommand(cmd *cobra.Command, fileA, fileB string) {
+	if update, _ := cmd.Flags().GetBool("update"); update {
+		fmt.Fprintln(os.Stderr, "Error: --update only works with a single baseline file")
+		os.Exit(2)
+	}
+	asJSON, _ := cmd.Flags().GetBool("json")
+	outputFile, _ := cmd.Flags().GetString("output")
+
+	// Step 1: Load both scans
+	scanA, err := loadScanFile(fileA)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fileA, err)
+		os.Exit(2)
+	}
+	scanB, err := loadScanFile(fileB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fileB, err)
+		os.Exit(2)
+	}
+
+	// Step 2: Compare them
+	comparison := compareMachines(scanA, fileA, scanB, fileB)
+
+	// Step 3: Save the report if requested, otherwise print it
+	if outputFile != "" {
+		err = saveMachineComparison(comparison, outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving report: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Printf("Report saved to: %s\n", outputFile)
+	} else if asJSON {
+		err = writeMachineComparisonJSON(os.Stdout, comparison)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		writeMachineComparison(os.Stdout, comparison)
+	}
+
+	// Step 4: Exit with 1 when the machines differ, like diff does
+	if !comparison.identical() {
+		os.Exit(1)
+	}
+}
+
+// machineInfo describes where one side of a two-machine comparison came from
+type machineInfo struct {
+	File      string    `json:"file"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	Programs  int       `json:"programs"`
+}
+
+// machineDifference is a program on both machines with different versions
+type machineDifference struct {
+	A Program `json:"a"`
+	B Program `json:"b"`
+}
+
+// machineComparison is the report of "winclone compare <a.json> <b.json>"
+type machineComparison struct {
+	MachineA           machineInfo         `json:"machineA"`
+	MachineB           machineInfo         `json:"machineB"`
+	OnlyA              []Program           `json:"onlyA"`
+	OnlyB              []Program           `json:"onlyB"`
+	VersionDifferences []machineDifference `json:"versionDifferences"`
+	Same               []Program           `json:"same"`
+}
+
+// identical reports whether both machines have the same programs and versions
+func (c machineComparison) identical() bool {
+	return len(c.OnlyA) == 0 && len(c.OnlyB) == 0 && len(c.VersionDifferences) == 0
+}
+
+// compareMachines sorts the programs of two scans into only-A, only-B,
+// different versions and same version
+// Install paths often differ between machines (other drive, other user),
+// so a program that only moved counts as the same
+func compareMachines(scanA ScanResult, fileA string, scanB ScanResult, fileB string) machineComparison {
+	diff := diffPrograms(scanA.Programs, scanB.Programs)
+
+	// Empty lists are written as [] rather than null, which is easier for scripts
+	comparison := machineComparison{
+		MachineA:           machineInfo{File: fileA, Hostname: scanA.Hostname, Timestamp: scanA.Timestamp, Programs: len(scanA.Programs)},
+		MachineB:           machineInfo{File: fileB, Hostname: scanB.Hostname, Timestamp: scanB.Timestamp, Programs: len(scanB.Programs)},
+		OnlyA:              append([]Program{}, diff.Removed...),
+		OnlyB:              append([]Program{}, diff.Added...),
+		VersionDifferences: []machineDifference{},
+		Same:               append([]Program{}, diff.Unchanged...),
+	}
+	for _, change := range diff.Changed {
+		if change.Old.Version != change.New.Version {
+			comparison.VersionDifferences = append(comparison.VersionDifferences, machineDifference{A: change.Old, B: change.New})
+		} else {
+			comparison.Same = append(comparison.Same, change.New)
+		}
+	}
+	sort.SliceStable(comparison.Same, func(i, j int) bool {
+		return strings.ToLower(comparison.Same[i].Name) < strings.ToLower(comparison.Same[j].Name)
+	})
+
+	return comparison
+}
+
+// machineName is how a machine is called in the text report:
+// its hostname, or the file name for bare-array scans that don't record one
+func machineName(info machineInfo) string {
+	if info.Hostname != "" {
+		return info.Hostname
+	}
+	return info.File
+}
+
+// writeMachineComparison writes the two-machine report as text
+func writeMachineComparison(w io.Writer, c machineComparison) {
+	nameA, nameB := machineName(c.MachineA), machineName(c.MachineB)
+
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "COMPARING %s WITH %s\n", nameA, nameB)
+	for _, info := range []machineInfo{c.MachineA, c.MachineB} {
+		scanned := "scan time unknown"
+		if !info.Timestamp.IsZero() {
+			scanned = "scanned " + info.Timestamp.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "  %s: %d programs, %s (%s)\n", machineName(info), info.Programs, scanned, info.File)
+	}
+	fmt.Fprintf(w, "%s\n\n", strings.Repeat("=", 50))
+
+	fmt.Fprintf(w, "Only on %s: %d\n", nameA, len(c.OnlyA))
+	for _, program := range c.OnlyA {
+		fmt.Fprintf(w, "  - %s\n", programLabel(program))
+	}
+
+	fmt.Fprintf(w, "\nOnly on %s: %d\n", nameB, len(c.OnlyB))
+	for _, program := range c.OnlyB {
+		fmt.Fprintf(w, "  + %s\n", programLabel(program))
+	}
+
+	fmt.Fprintf(w, "\nDifferent versions: %d\n", len(c.VersionDifferences))
+	for _, difference := range c.VersionDifferences {
+		fmt.Fprintf(w, "  ~ %s: %s on %s, %s on %s\n", difference.B.Name,
+			versionOrUnknown(difference.A.Version), nameA, versionOrUnknown(difference.B.Version), nameB)
+	}
+
+	fmt.Fprintf(w, "\nSame version on both: %d\n", len(c.Same))
+	for _, program := range c.Same {
+		fmt.Fprintf(w, "  = %s\n", programLabel(program))
+	}
+}
+
+// writeMachineComparisonJSON writes the two-machine report as indented JSON
+func writeMachineComparisonJSON(w io.Writer, c machineComparison) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err := encoder.Encode(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return nil
+}
+
+// saveMachineComparison saves the two-machine report: JSON for a .json file, text otherwise
+func saveMachineComparison(c machineComparison, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		err = writeMachineComparisonJSON(file, c)
+		if err != nil {
+			return err
+		}
+	} else {
+		writeMachineComparison(file, c)
+	}
+	return file.Commit()
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	// Add the --update flag to accept the changes after reporting them
+	compareCmd.Flags().Bool("update", false, "Overwrite the baseline with this scan after reporting")
+
+	// Add the --json and --output flags for the two-machine report
+	compareCmd.Flags().Bool("json", false, "Print the two-machine report as JSON")
+	compareCmd.Flags().StringP("output", "o", "", "Save the two-machine report to a file (JSON for .json, text otherwise)")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(compareCmd)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// configFileName is the config file looked for in the working directory,
+// then in %APPDATA%\winclone
+const configFileName = "winclone.toml"
+
+// defaultConfig is the file written by "winclone config init"
+// Every setting is commented out, so the new file changes nothing until edited
+const defaultConfig = `# WinClone configuration
+# Each [table] is a command, and each key is one of its flags without the "--".
+# Flags typed on the command line always win over these defaults.
+# See "winclone <command> --help" for every flag.
+
+[scan]
+# sort = "size"                           # name, version, publisher, size or date
+# output-format = "csv"                   # Format for -o (json, ndjson, text, csv, yaml, xml, markdown, html, sqlite)
+# exclude = ["Update for", "re:^KB\\d+"]  # Hide programs whose name contains these
+# include-system = false                  # Show entries hidden from Add or Remove Programs
+# verbose = false
+
+[export]
+# format = "winget"                       # winget, chocolatey or scoop
+`
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the winclone.toml configuration file",
+	Long: `WinClone reads default flag values from winclone.toml, so options you
+always use don't have to be typed every time.
+
+The file is looked for in the current directory first, then in
+%APPDATA%\winclone\winclone.toml. Each [table] is a command and each key is
+one of its flags, e.g.:
+
+  [scan]
+  sort = "size"
+  exclude = ["Update for"]
+  include-system = true
+
+Flags given on the command line override the file.
+
+Examples:
+  winclone config init           # Write a commented winclone.toml here
+  winclone config init --global  # Write it to %APPDATA%\winclone instead
+  winclone config path           # Show which file is used`,
+}
+
+// configInitCmd represents the "config init" command
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a default winclone.toml to edit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone config init"
+		global, _ := cmd.Flags().GetBool("global")
+		force, _ := cmd.Flags().GetBool("force")
+
+		filename := configFileName
+		if global {
+			configDir, err := os.UserConfigDir()
+			if err != nil {
+				return fmt.Errorf("failed to find the user config directory: %v", err)
+			}
+			filename = filepath.Join(configDir, "winclone", configFileName)
+			err = os.MkdirAll(filepath.Dir(filename), 0755)
+			if err != nil {
+				return fmt.Errorf("failed to create config directory: %v", err)
+			}
+		}
+
+		// Don't overwrite settings someone has already edited
+		if _, err := os.Stat(filename); err == nil && !force {
+			return fmt.Errorf("%s already exists (use --force to overwrite it)", filename)
+		}
+
+		file, err := createOutput(filename)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", filename, err)
+		}
+		defer file.Close() // Throws the temporary file away unless it was committed
+
+		_, err = io.WriteString(file, defaultConfig)
+		if err == nil {
+			err = file.Commit()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", filename, err)
+		}
+
+		fmt.Printf("Config file written to: %s\n", filename)
+		return nil
+	},
+}
+
+// configPathCmd represents the "config path" command
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show which winclone.toml is used",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone config path"
+		filename := findConfigFile()
+		if filename == "" {
+			fmt.Println("No config file found (run \"winclone config init\" to create one)")
+			return nil
+		}
+		fmt.Println(filename)
+		return nil
+	},
+}
+
+// findConfigFile returns the config file to use, or "" if there is none
+// The working directory wins over the user config directory, so a project
+// folder can carry its own settings
+func findConfigFile() string {
+	candidates := []string{configFileName}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(configDir, "winclone", configFileName))
+	}
+
+	for _, filename := range candidates {
+		if _, err := os.Stat(filename); err == nil {
+			return filename
+		}
+	}
+	return ""
+}
+
+// applyConfig sets the flags of cmd from its table in the config file
+// Flags already given on the command line are left alone
+// Unknown keys are an error, so a typo doesn't silently do nothing
+func applyConfig(cmd *cobra.Command) error {
+	filename := findConfigFile()
+	if filename == "" {
+		return nil
+	}
+
+	var config map[string]map[string]any
+	_, err := toml.DecodeFile(filename, &config)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // Removed since we looked for it
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", filename, err)
+	}
+
+	// "winclone snapshot list" reads the ["snapshot list"] table
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	settings := config[name]
+
+	// Apply in a fixed order so errors are reported consistently
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// A config value gives way to a command-line flag it can't be combined with
+	// (e.g. a configured sort with "scan --stream"), so check before anything is set
+	overridden := map[string]bool{}
+	for _, key := range keys {
+		if flag := cmd.Flags().Lookup(key); flag != nil {
+			overridden[key] = conflictsWithCommandLine(cmd, flag.Annotations[mutuallyExclusiveAnnotation])
+		}
+	}
+
+	for _, key := range keys {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil {
+			return fmt.Errorf("%s: [%s] has no flag named %q", filename, name, key)
+		}
+		if flag.Changed || overridden[key] {
+			continue
+		}
+
+		// A TOML array sets a repeatable flag like --exclude once per item
+		values := []any{settings[key]}
+		if list, ok := settings[key].([]any); ok {
+			values = list
+		}
+		for _, value := range values {
+			err = cmd.Flags().Set(key, fmt.Sprint(value))
+			if err != nil {
+				return fmt.Errorf("%s: [%s] %s: %v", filename, name, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mutuallyExclusiveAnnotation is where cobra records a flag's MarkFlagsMutuallyExclusive groups
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// conflictsWithCommandLine reports whether any flag in the mutually exclusive
+// groups (each a space-separated list of flag names) was set on the command line
+func conflictsWithCommandLine(cmd *cobra.Command, groups []string) bool {
+	for _, group := range groups {
+		for _, name := range strings.Fields(group) {
+			if cmd.Flags().Changed(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configPathCmd)
+
+	// Add the --global flag to write the config to the user config directory
+	configInitCmd.Flags().Bool("global", false, "Write to %APPDATA%\\winclone instead of the current directory")
+
+	// Add the --force flag to replace an existing config file
+	configInitCmd.Flags().Bool("force", false, "Overwrite an existing config file")
+}
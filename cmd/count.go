@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// countCmd represents the count command
+var countCmd = &cobra.Command{
+	Use:   "count",
+	Short: "Print how many programs are installed, for scripts",
+	Long: `Scan the registry and print the number of installed programs as a
+single integer, so shell scripts can use it directly.
+
+The same filters as the scan command narrow down what is counted:
+- --filter: Only counts programs whose name or publisher contains the text
+- --scope: machine, user or all (default: all)
+- --arch (or --filter-arch, or --architecture): x64 or x86
+
+--details prints a breakdown instead: how many programs came from each
+registry location and how many have a version, install path and publisher
+recorded. --json prints the same breakdown as JSON.
+
+Examples:
+  winclone count                          # Print the number of programs
+  winclone count --scope user             # Count per-user installs only
+  winclone count --arch x86               # Count 32-bit programs
+  winclone count --filter microsoft       # Count Microsoft programs
+  winclone count --details                # Print the breakdown
+  winclone count --json                   # Print the breakdown as JSON
+
+In a script:
+  if [ $(winclone count) -gt 500 ]; then echo "That's a lot"; fi`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone count"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		details, _ := cmd.Flags().GetBool("details")
+		filter, _ := cmd.Flags().GetString("filter")
+		arch, _ := cmd.Flags().GetString("filter-arch")
+		for _, alias := range []string{"arch", "architecture"} {
+			if cmd.Flags().Changed(alias) {
+				arch, _ = cmd.Flags().GetString(alias)
+			}
+		}
+		scope, _ := cmd.Flags().GetString("scope")
+		scope = strings.ToLower(scope)
+		err := validateScope(scope)
+		if err != nil {
+			return err
+		}
+
+		// The plain number is meant for $(winclone count), so it gets no header
+		if details {
+			fmt.Fprintln(os.Stderr, "WinClone - Counting installed programs...")
+			fmt.Fprintln(os.Stderr, "==========================================")
+		}
+
+		// Count what "winclone scan" would list
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+		if filter != "" {
+			programs = filterByText(programs, filter)
+		}
+		programs = filterByScope(programs, scope)
+		if arch != "" {
+			programs, err = filterByArchitecture(programs, arch)
+			if err != nil {
+				return err
+			}
+		}
+
+		counts := countPrograms(programs)
+
+		switch {
+		case asJSON:
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+			err = encoder.Encode(counts)
+			if err != nil {
+				return fmt.Errorf("failed to encode JSON: %v", err)
+			}
+		case details:
+			displayCounts(counts)
+		default:
+			fmt.Println(counts.Total)
+		}
+
+		return nil
+	},
+}
+
+// programCounts is the breakdown printed by "winclone count"
+type programCounts struct {
+	Total         int `json:"total"`
+	X64           int `json:"x64"`
+	X86           int `json:"x86"`
+	PerUser       int `json:"perUser"`
+	WithVersion   int `json:"withVersion"`
+	WithPath      int `json:"withPath"`
+	WithPublisher int `json:"withPublisher"`
+}
+
+// countPrograms works out the totals for a program list
+func countPrograms(programs []Program) programCounts {
+	counts := programCounts{Total: len(programs)}
+
+	for _, program := range programs {
+		// Count by registry location
+		switch {
+		case program.Source == sourceHKCU:
+			counts.PerUser++
+		case program.Architecture == "x64":
+			counts.X64++
+		case program.Architecture == "x86":
+			counts.X86++
+		}
+
+		// Count how many have each optional field filled in
+		if program.Version != "" {
+			counts.WithVersion++
+		}
+		if program.Path != "" {
+			counts.WithPath++
+		}
+		if program.Publisher != "" {
+			counts.WithPublisher++
+		}
+	}
+
+	return counts
+}
+
+// displayCounts prints the totals in a human-readable layout
+func displayCounts(counts programCounts) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("Total programs: %d\n", counts.Total)
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	fmt.Printf("64-bit:         %d\n", counts.X64)
+	fmt.Printf("32-bit:         %d\n", counts.X86)
+	fmt.Printf("Per-user:       %d\n", counts.PerUser)
+	fmt.Println()
+	fmt.Printf("With version:   %d\n", counts.WithVersion)
+	fmt.Printf("With path:      %d\n", counts.WithPath)
+	fmt.Printf("With publisher: %d\n", counts.WithPublisher)
+}
+
+func init() {
+	rootCmd.AddCommand(countCmd)
+
+	// Add the --details and --json flags for the full breakdown
+	countCmd.Flags().Bool("details", false, "Print a breakdown by location and recorded details")
+	countCmd.Flags().Bool("json", false, "Print the breakdown as JSON")
+
+	// Add the same filters as the scan command
+	countCmd.Flags().StringP("filter", "f", "", "Only count programs whose name or publisher contains this text")
+	countCmd.Flags().String("scope", scopeAll, "Only count machine-wide or per-user programs (machine, user, all)")
+	countCmd.Flags().String("filter-arch", "", "Only count programs of one architecture: x64 or x86")
+	countCmd.Flags().String("arch", "", "Same as --filter-arch")
+	countCmd.Flags().String("architecture", "", "Same as --filter-arch")
+	countCmd.MarkFlagsMutuallyExclusive("filter-arch", "arch", "architecture")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(countCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// countVisibleEntries counts the uninstall entries Control Panel would list:
+// those with a DisplayName that aren't marked SystemComponent=1
+// view picks the 32-bit or 64-bit registry view (0 for keys that aren't split)
+func countVisibleEntries(root registry.Key, keyPath string, view uint32) (int, error) {
+	key, err := registry.OpenKey(root, keyPath, registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE|view)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close()
+
+	subkeyNames, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read subkey names: %w", err)
+	}
+
+	count := 0
+	for _, subkeyName := range subkeyNames {
+		subkey, err := registry.OpenKey(key, subkeyName, registry.QUERY_VALUE)
+		if err != nil {
+			continue // We can't see it either way, so it doesn't change the comparison
+		}
+
+		name, _, err := subkey.GetStringValue("DisplayName")
+		systemComponent, _, _ := subkey.GetIntegerValue("SystemComponent")
+		subkey.Close()
+
+		if err == nil && strings.TrimSpace(name) != "" && systemComponent != 1 {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// displayCrossCheck compares the scan result with the number of entries
+// Control Panel would show and explains the most likely reasons for a gap
+// It's only a diagnostic: the program list itself is never changed
+func displayCrossCheck(programs []Program, skipped []skippedEntry) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("CONTROL PANEL CROSS-CHECK\n")
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	// Step 1: Count visible entries in every location Control Panel reads
+	// (the same locations the scan reads, so 32-bit Windows isn't counted twice)
+	expected := 0
+	var errs []error
+	for _, loc := range locationsForOS(defaultLocations(), is64BitWindows()) {
+		count, err := countVisibleEntries(loc.Root, loc.Path, loc.View)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		expected += count
+	}
+
+	fmt.Printf("Control Panel should show about %d programs\n", expected)
+	fmt.Printf("WinClone found %d programs\n", len(programs))
+
+	for _, err := range errs {
+		fmt.Printf("Note: part of the cross-check failed: %v\n", err)
+	}
+
+	// Step 2: Only warn when the gap is more than about 10%
+	if len(programs)*10 >= expected*9 {
+		fmt.Println("\nThe counts are close - nothing looks to be missing.")
+		return
+	}
+
+	fmt.Printf("\nWarning: WinClone found %d fewer programs than Control Panel. Likely causes:\n", expected-len(programs))
+
+	// Step 3: Point at the causes we can actually see
+	causes := 0
+	accessDenied := 0
+	for _, entry := range skipped {
+		if entry.Subkey == "" {
+			fmt.Printf("  - %s could not be scanned: %s\n", entry.Location, entry.Reason)
+			causes++
+		} else if entry.AccessDenied {
+			accessDenied++
+		}
+	}
+	if accessDenied > 0 {
+		fmt.Printf("  - %d entries were skipped because access was denied (try running as administrator)\n", accessDenied)
+		causes++
+	}
+
+	if causes == 0 {
+		fmt.Println("  - No obvious cause found; some entries may have been unreadable or filtered")
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import "strings"
+
+// dedupPrograms collapses entries with the same Name, Version and Publisher
+// Some installers (e.g. .NET runtimes) register under both the 64-bit and the
+// WOW6432Node key, so the same program shows up twice. The x64 entry is kept
+// (or the one with more details) in the position where the program was first
+// seen, and any details it's missing are filled in from the duplicate
+// It returns the deduplicated list and how many entries were removed
+func dedupPrograms(programs []Program) ([]Program, int) {
+	var unique []Program
+	index := make(map[string]int)
+	removed := 0
+
+	for _, program := range programs {
+		key := strings.ToLower(program.Name) + "\x00" +
+			strings.ToLower(program.Version) + "\x00" +
+			strings.ToLower(program.Publisher)
+
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(unique)
+			unique = append(unique, program)
+			continue
+		}
+
+		removed++
+		if preferEntry(program, unique[i]) {
+			unique[i] = mergePrograms(program, unique[i])
+		} else {
+			unique[i] = mergePrograms(unique[i], program)
+		}
+	}
+
+	return unique, removed
+}
+
+// preferEntry reports whether a should be kept over its duplicate b
+// The x64 entry wins; otherwise the one with more details does
+func preferEntry(a, b Program) bool {
+	if a.Architecture != b.Architecture {
+		if a.Architecture == "x64" {
+			return true
+		}
+		if b.Architecture == "x64" {
+			return false
+		}
+	}
+	return completeness(a) > completeness(b)
+}
+
+// mergePrograms returns keep with any empty details filled in from other
+func mergePrograms(keep, other Program) Program {
+	if keep.Path == "" {
+		keep.Path = other.Path
+	}
+	if keep.Publisher == "" {
+		keep.Publisher = other.Publisher
+	}
+	if keep.SizeKB == 0 {
+		keep.SizeKB = other.SizeKB
+	}
+	if keep.InstallDate == nil {
+		keep.InstallDate = other.InstallDate
+	}
+	return keep
+}
+
+// completeness scores how much information an entry has
+// A known install path counts the most, since it's the hardest to find elsewhere
+func completeness(program Program) int {
+	score := 0
+	if program.Path != "" {
+		score += 2
+	}
+	if program.Publisher != "" {
+		score++
+	}
+	if program.SizeKB > 0 {
+		score++
+	}
+	if program.InstallDate != nil {
+		score++
+	}
+	return score
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupPrograms(t *testing.T) {
+	installed := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		programs []Program
+		want     []Program
+		removed  int
+	}{
+		{
+			name: "no duplicates",
+			programs: []Program{
+				{Name: "Git", Version: "2.43.0"},
+				{Name: "7-Zip", Version: "23.01"},
+			},
+			want: []Program{
+				{Name: "Git", Version: "2.43.0"},
+				{Name: "7-Zip", Version: "23.01"},
+			},
+		},
+		{
+			name: "x64 kept over x86",
+			programs: []Program{
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x86", Path: `C:\Program Files (x86)\dotnet`},
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x64"},
+			},
+			want: []Program{
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x64", Path: `C:\Program Files (x86)\dotnet`},
+			},
+			removed: 1,
+		},
+		{
+			name: "x64 kept when it comes first",
+			programs: []Program{
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x64"},
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x86", SizeKB: 1024},
+			},
+			want: []Program{
+				{Name: ".NET Runtime", Version: "8.0.1", Architecture: "x64", SizeKB: 1024},
+			},
+			removed: 1,
+		},
+		{
+			name: "more details win on the same architecture",
+			programs: []Program{
+				{Name: "Git", Version: "2.43.0", Architecture: "x64", SizeKB: 1024},
+				{Name: "Git", Version: "2.43.0", Architecture: "x64", Path: `C:\Program Files\Git`, InstallDate: &installed},
+			},
+			want: []Program{
+				{Name: "Git", Version: "2.43.0", Architecture: "x64", Path: `C:\Program Files\Git`, InstallDate: &installed, SizeKB: 1024},
+			},
+			removed: 1,
+		},
+		{
+			name: "key is case-insensitive",
+			programs: []Program{
+				{Name: "Git", Version: "2.43.0", Publisher: "The Git Development Community"},
+				{Name: "GIT", Version: "2.43.0", Publisher: "the git development community"},
+			},
+			want: []Program{
+				{Name: "Git", Version: "2.43.0", Publisher: "The Git Development Community"},
+			},
+			removed: 1,
+		},
+		{
+			name: "different versions are kept",
+			programs: []Program{
+				{Name: "Python", Version: "3.11.7"},
+				{Name: "Python", Version: "3.12.1"},
+			},
+			want: []Program{
+				{Name: "Python", Version: "3.11.7"},
+				{Name: "Python", Version: "3.12.1"},
+			},
+		},
+		{
+			name: "different publishers are kept",
+			programs: []Program{
+				{Name: "Updater", Version: "1.0", Publisher: "Contoso"},
+				{Name: "Updater", Version: "1.0", Publisher: "Fabrikam"},
+			},
+			want: []Program{
+				{Name: "Updater", Version: "1.0", Publisher: "Contoso"},
+				{Name: "Updater", Version: "1.0", Publisher: "Fabrikam"},
+			},
+		},
+		{
+			name: "first position is kept",
+			programs: []Program{
+				{Name: "Zoom", Version: "5.17", Architecture: "x86"},
+				{Name: "Git", Version: "2.43.0"},
+				{Name: "Zoom", Version: "5.17", Architecture: "x64"},
+				{Name: "Zoom", Version: "5.17", Architecture: "x86"},
+			},
+			want: []Program{
+				{Name: "Zoom", Version: "5.17", Architecture: "x64"},
+				{Name: "Git", Version: "2.43.0"},
+			},
+			removed: 2,
+		},
+		{
+			name: "empty list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := dedupPrograms(tt.programs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupPrograms() = %+v, want %+v", got, tt.want)
+			}
+			if removed != tt.removed {
+				t.Errorf("dedupPrograms() removed %d, want %d", removed, tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Compare two saved scans and show what changed",
+	Long: `Load two JSON files saved with "winclone scan -o file.json" and show
+what changed between them:
+- Added: programs only in the second file
+- Removed: programs only in the first file
+- Changed: programs in both, but with a different version or install path
+
+Programs are matched by name (case-insensitive). Both wrapped and bare-array
+JSON files can be compared. A file written by a newer WinClone with an
+incompatible schemaVersion is refused, as it is by compare and restore.
+
+With -o the diff is saved instead of printed: as JSON for a .json file,
+otherwise as the same text report shown on screen.
+
+Exit codes (so the diff can gate migration or CI scripts):
+  0  The scans are the same
+  1  There are differences
+  2  A file could not be read or the diff could not be saved
+
+Examples:
+  winclone diff last-week.json today.json             # What changed this week?
+  winclone diff old-pc.json new-pc.json -o diff.json  # Save the diff as JSON
+  winclone diff old-pc.json new-pc.json -o diff.txt   # Save the text report`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// This function runs when the user types "winclone diff <old> <new>"
+		oldFile, newFile := args[0], args[1]
+
+		// Step 1: Load both scans
+		oldScan, err := loadScanFile(oldFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", oldFile, err)
+			os.Exit(2)
+		}
+		newScan, err := loadScanFile(newFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", newFile, err)
+			os.Exit(2)
+		}
+
+		// Step 2: Compare them
+		diff := diffPrograms(oldScan.Programs, newScan.Programs)
+
+		// Step 3: Save the diff if requested, otherwise print it
+		outputFile, _ := cmd.Flags().GetString("output")
+		if outputFile != "" {
+			if strings.HasSuffix(strings.ToLower(outputFile), ".json") {
+				err = saveDiffToJSON(diff, outputFile)
+			} else {
+				err = saveDiffToText(diff, oldFile, newFile, outputFile)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving diff: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Printf("Diff saved to: %s\n", outputFile)
+		} else {
+			writeDiffReport(os.Stdout, diff, oldFile, newFile)
+		}
+
+		// Step 4: Exit with 1 when something changed, so scripts can check $LASTEXITCODE
+		if !diff.isEmpty() {
+			os.Exit(1)
+		}
+	},
+}
+
+// writeDiffReport writes the diff with a heading naming both files
+func writeDiffReport(w io.Writer, diff programDiff, oldFile, newFile string) {
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "CHANGES FROM %s TO %s\n", oldFile, newFile)
+	fmt.Fprintf(w, "%s\n\n", strings.Repeat("=", 50))
+
+	if diff.isEmpty() {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+	writeDiff(w, diff)
+}
+
+// saveDiffToText saves the diff as the same text report shown on screen
+func saveDiffToText(diff programDiff, oldFile, newFile, filename string) error {
+	// Create the text file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	writeDiffReport(file, diff, oldFile, newFile)
+	return file.Commit()
+}
+
+// saveDiffToJSON saves a diff as an object with "added", "removed" and "changed" lists
+func saveDiffToJSON(diff programDiff, filename string) error {
+	// Create the JSON file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	// Empty lists are written as [] rather than null, which is easier for scripts
+	if diff.Added == nil {
+		diff.Added = []Program{}
+	}
+	if diff.Removed == nil {
+		diff.Removed = []Program{}
+	}
+	if diff.Changed == nil {
+		diff.Changed = []programChange{}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err = encoder.Encode(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+
+	return file.Commit()
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("output", "o", "", "Save the diff to a file instead of printing it (JSON: .json, otherwise text)")
+}
@@ -0,0 +1,525 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the installed programs for a package manager",
+	Long: `Scan the installed programs and write a file a package manager can
+use to install them again on a new machine.
+
+Formats:
+- winget: A "winget import" file (default name winget-packages.json).
+  Install everything on the new machine with: winget import -i winget-packages.json
+- chocolatey: A Chocolatey packages.config (default name packages.config).
+  Install everything with: choco install packages.config -y
+  Programs "choco search" can't find get an ID guessed from their name
+  (e.g. "Google Chrome" -> googlechrome), marked with a comment in the file
+- scoop: A list of Scoop apps, {"apps": ["git", "python", ...]} (default name
+  scoop-apps.json). Scoop has no search WinClone can use, so only programs in
+  WinClone's built-in list of common apps or in --scoop-map-file are included.
+  Install everything with:
+  (Get-Content scoop-apps.json | ConvertFrom-Json).apps | ForEach-Object { scoop install $_ }
+
+Programs are matched to packages by name. A mapping file can pin the right
+package for names the lookup gets wrong or can't find: a JSON object from
+DisplayName to package ID, e.g. {"Git": "Git.Git", "7-Zip 23.01 (x64)": "7zip.7zip"}.
+Use --winget-map-file, --choco-map-file or --scoop-map-file to pass one. Names
+in the mapping file are compared case-insensitively, with and without version
+numbers. Guessed IDs are listed on stderr, and so are programs without a
+package, unless --unmapped-output names a file to write them to.
+
+--dry-run prints the file to stdout instead of writing it, and names the file
+it would have written, so it can be reviewed first. Nothing is written to disk,
+not even the --unmapped-output list.
+
+Examples:
+  winclone export --format winget                                  # Writes winget-packages.json
+  winclone export --format winget -o setup.json                    # Choose the file name
+  winclone export --format winget --winget-map-file my-ids.json    # Use your own IDs first
+  winclone export --format chocolatey --choco-map-file choco.json  # Writes packages.config
+  winclone export --format scoop --unmapped-output manual.txt      # Writes scoop-apps.json
+  winclone export --format chocolatey --dry-run                    # Show packages.config first`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone export"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		format, _ := cmd.Flags().GetString("format")
+		outputFile, _ := cmd.Flags().GetString("output")
+
+		format = strings.ToLower(format)
+		if format == "choco" {
+			format = "chocolatey"
+		}
+		defaultFile, ok := exportDefaultFiles[format]
+		if !ok {
+			return fmt.Errorf("unknown --format %q (valid formats: winget, chocolatey, scoop)", format)
+		}
+		if outputFile == "" {
+			outputFile = defaultFile
+		}
+
+		// A dry run prints the file to stdout instead, and says where it would have gone
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		target := outputFile
+		if dryRun {
+			outputFile = stdoutName
+		}
+
+		// Read the mapping file before the scan so a typo fails fast
+		mapFlag := "winget-map-file"
+		switch format {
+		case "chocolatey":
+			mapFlag = "choco-map-file"
+		case "scoop":
+			mapFlag = "scoop-map-file"
+		}
+		mapFile, _ := cmd.Flags().GetString(mapFlag)
+		nameMap, err := loadNameMap(mapFile)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(os.Stderr, "WinClone - Scanning installed programs...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+		sortPrograms(programs, "name")
+
+		// Use the mapping file first, then the package manager's own lookup
+		matches, unmatched := matchFromNameMap(programs, nameMap)
+		var guessed map[string]bool
+		switch format {
+		case "winget":
+			findMissingWingetIDs(unmatched, matches)
+			err = saveWingetImport(programs, matches, outputFile)
+		case "chocolatey":
+			guessed = findMissingChocolateyIDs(unmatched, matches)
+			err = saveChocolateyConfig(programs, matches, guessed, outputFile)
+		case "scoop":
+			findScoopApps(unmatched, matches)
+			err = saveScoopApps(programs, matches, outputFile)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save %s: %v", outputFile, err)
+		}
+
+		// Keep a list of the programs that need a manual install, if asked to
+		unmappedFile, _ := cmd.Flags().GetString("unmapped-output")
+		if dryRun {
+			unmappedFile = "" // List them on stderr instead
+		}
+		if unmappedFile != "" {
+			err = saveUnmapped(programs, matches, unmappedFile)
+			if err != nil {
+				return fmt.Errorf("failed to save %s: %v", unmappedFile, err)
+			}
+			fmt.Fprintf(os.Stderr, "Programs without a package saved to: %s\n", unmappedFile)
+		}
+		reportUnmatched(programs, matches, guessed, unmappedFile == "")
+		if dryRun {
+			// stdout holds just the file, so the summary goes to stderr
+			fmt.Fprintf(os.Stderr, "\nMatched %d of %d programs to %s packages\n", len(matches)-len(guessed), len(programs), format)
+			fmt.Fprintf(os.Stderr, "Dry run: nothing was written. Without --dry-run this would be saved to: %s\n", target)
+			if wanted, _ := cmd.Flags().GetString("unmapped-output"); wanted != "" {
+				fmt.Fprintf(os.Stderr, "Programs without a package would be saved to: %s\n", wanted)
+			}
+		} else if outputFile != stdoutName {
+			fmt.Printf("\nMatched %d of %d programs to %s packages\n", len(matches)-len(guessed), len(programs), format)
+			fmt.Printf("Saved to: %s\n", outputFile)
+		}
+
+		return nil
+	},
+}
+
+// exportDefaultFiles are the supported --format values and the file each writes by default
+var exportDefaultFiles = map[string]string{
+	"winget":     "winget-packages.json",
+	"chocolatey": "packages.config",
+	"scoop":      "scoop-apps.json",
+}
+
+// findMissingWingetIDs looks the unmatched programs up in winget, if it's installed,
+// and adds what it finds to matches
+func findMissingWingetIDs(unmatched []Program, matches map[string]string) {
+	_, err := exec.LookPath("winget")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: winget was not found, so only the mapping file is used")
+		return
+	}
+	if len(unmatched) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\nLooking up %d programs in winget...\n", len(unmatched))
+	for name, id := range findWingetIDs(unmatched) {
+		matches[name] = id
+	}
+}
+
+// loadNameMap reads a JSON object mapping DisplayName to package ID
+// Keys are lowercased so lookups ignore case; an empty filename means no mapping
+func loadNameMap(filename string) (map[string]string, error) {
+	nameMap := make(map[string]string)
+	if filename == "" {
+		return nameMap, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %v", err)
+	}
+	var raw map[string]string
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file %s (expected {\"DisplayName\": \"PackageId\"}): %v", filename, err)
+	}
+
+	for name, id := range raw {
+		nameMap[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(id)
+	}
+	return nameMap, nil
+}
+
+// matchFromNameMap looks each program up in the mapping, by full name and then
+// by the name without version numbers ("Git 2.43.0" finds an entry for "Git")
+// It returns the matches (program name -> ID) and the programs still unmatched
+func matchFromNameMap(programs []Program, nameMap map[string]string) (map[string]string, []Program) {
+	matches := make(map[string]string)
+	var unmatched []Program
+
+	for _, program := range programs {
+		id := nameMap[strings.ToLower(program.Name)]
+		if id == "" {
+			id = nameMap[strings.ToLower(cleanProgramName(program.Name))]
+		}
+		if id != "" {
+			matches[program.Name] = id
+		} else {
+			unmatched = append(unmatched, program)
+		}
+	}
+	return matches, unmatched
+}
+
+// reportUnmatched lists the programs whose package ID was only guessed from
+// the name on stderr, and (if listUnmatched is set) the ones without a package
+func reportUnmatched(programs []Program, matches map[string]string, guessed map[string]bool, listUnmatched bool) {
+	var unmatched, guesses []string
+	for _, program := range programs {
+		if _, ok := matches[program.Name]; !ok {
+			unmatched = append(unmatched, programLabel(program))
+		} else if guessed[program.Name] {
+			guesses = append(guesses, fmt.Sprintf("%s -> %s", programLabel(program), matches[program.Name]))
+		}
+	}
+
+	if len(guesses) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d package IDs were guessed from the program name (check them, or add them to a mapping file):\n", len(guesses))
+		for _, line := range guesses {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
+		}
+	}
+	if listUnmatched && len(unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d programs have no package (install these manually, or add them to a mapping file):\n", len(unmatched))
+		for _, label := range unmatched {
+			fmt.Fprintf(os.Stderr, "  %s\n", label)
+		}
+	}
+}
+
+// wingetImport is the document read by "winget import"
+// It follows https://aka.ms/winget-packages.schema.2.0.json
+type wingetImport struct {
+	Schema       string         `json:"$schema"`
+	CreationDate string         `json:"CreationDate"`
+	Sources      []wingetSource `json:"Sources"`
+}
+
+// wingetSource is one package source and the packages to install from it
+type wingetSource struct {
+	Packages      []wingetImportPackage `json:"Packages"`
+	SourceDetails wingetSourceDetails   `json:"SourceDetails"`
+}
+
+// wingetImportPackage is one package to install
+type wingetImportPackage struct {
+	PackageIdentifier string `json:"PackageIdentifier"`
+}
+
+// wingetSourceDetails identifies the source; these are the values for the
+// default "winget" community repository
+type wingetSourceDetails struct {
+	Argument   string `json:"Argument"`
+	Identifier string `json:"Identifier"`
+	Name       string `json:"Name"`
+	Type       string `json:"Type"`
+}
+
+// saveWingetImport writes the matched packages as a winget import file
+// Several registry entries can map to one package, so each ID is written once
+func saveWingetImport(programs []Program, matches map[string]string, filename string) error {
+	document := wingetImport{
+		Schema:       "https://aka.ms/winget-packages.schema.2.0.json",
+		CreationDate: time.Now().Format(time.RFC3339),
+		Sources: []wingetSource{{
+			Packages: []wingetImportPackage{},
+			SourceDetails: wingetSourceDetails{
+				Argument:   "https://cdn.winget.microsoft.com/cache",
+				Identifier: "Microsoft.Winget.Source_8wekyb3d8bbwe",
+				Name:       "winget",
+				Type:       "Microsoft.PreIndexed.Package",
+			},
+		}},
+	}
+
+	written := make(map[string]bool)
+	for _, program := range programs {
+		id, ok := matches[program.Name]
+		if !ok || written[strings.ToLower(id)] {
+			continue
+		}
+		written[strings.ToLower(id)] = true
+		document.Sources[0].Packages = append(document.Sources[0].Packages, wingetImportPackage{PackageIdentifier: id})
+	}
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err = encoder.Encode(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return file.Commit()
+}
+
+// findMissingChocolateyIDs finds Chocolatey IDs for the unmatched programs and
+// adds them to matches: with "choco search" if Chocolatey is installed, and
+// otherwise (or when the search finds nothing) by guessing from the name
+// It returns the programs whose ID was guessed
+func findMissingChocolateyIDs(unmatched []Program, matches map[string]string) map[string]bool {
+	guessed := make(map[string]bool)
+
+	_, err := exec.LookPath("choco")
+	canSearch := err == nil
+	if !canSearch {
+		fmt.Fprintln(os.Stderr, "Warning: choco was not found, so package IDs are guessed from program names")
+	} else if len(unmatched) > 0 {
+		fmt.Fprintf(os.Stderr, "\nLooking up %d programs in Chocolatey...\n", len(unmatched))
+	}
+
+	for _, program := range unmatched {
+		name := cleanProgramName(program.Name)
+		id := ""
+		if canSearch {
+			id = searchChocolatey(name)
+		}
+		if id == "" {
+			id = chocolateyIDFromName(name)
+			if id != "" {
+				guessed[program.Name] = true
+			}
+		}
+		if id != "" {
+			matches[program.Name] = id
+		}
+	}
+	return guessed
+}
+
+// chocolateyIDFromName guesses a Chocolatey package ID from a program name
+// Most IDs are the name in lowercase without spaces, e.g. "Google Chrome" ->
+// "googlechrome" and "Notepad++" -> "notepadplusplus"
+func chocolateyIDFromName(name string) string {
+	var id strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			id.WriteRune(r)
+		case r == '+':
+			id.WriteString("plus")
+		}
+	}
+	return strings.Trim(id.String(), ".-")
+}
+
+// saveChocolateyConfig writes the matched packages as a Chocolatey packages.config
+// Install everything with "choco install packages.config -y". Versions are left
+// out because registry versions rarely match Chocolatey's package versions;
+// guessed IDs get a comment so they're easy to review
+func saveChocolateyConfig(programs []Program, matches map[string]string, guessed map[string]bool, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	_, err = io.WriteString(file, xml.Header)
+	if err != nil {
+		return fmt.Errorf("failed to write XML: %v", err)
+	}
+	fmt.Fprintf(file, "<packages>\n")
+
+	// Several registry entries can map to one package, so each ID is written once
+	written := make(map[string]bool)
+	for _, program := range programs {
+		id, ok := matches[program.Name]
+		if !ok || written[strings.ToLower(id)] {
+			continue
+		}
+		written[strings.ToLower(id)] = true
+
+		if guessed[program.Name] {
+			// "--" isn't allowed inside XML comments
+			fmt.Fprintf(file, "  <!-- guessed from %q -->\n", strings.ReplaceAll(program.Name, "--", "- -"))
+		}
+		fmt.Fprintf(file, "  <package id=\"%s\" />\n", xmlAttr(id))
+	}
+
+	fmt.Fprintf(file, "</packages>\n")
+	return file.Commit()
+}
+
+// scoopApps maps common program names (without versions, lowercase) to Scoop apps
+// Apps outside Scoop's default "main" bucket carry their bucket, e.g. "extras/vscode",
+// which "scoop install" accepts once that bucket has been added
+var scoopApps = map[string]string{
+	"7-zip":                        "7zip",
+	"audacity":                     "extras/audacity",
+	"git":                          "git",
+	"gimp":                         "extras/gimp",
+	"google chrome":                "extras/googlechrome",
+	"keepass password safe":        "extras/keepass",
+	"microsoft visual studio code": "extras/vscode",
+	"mozilla firefox":              "extras/firefox",
+	"node.js":                      "nodejs",
+	"notepad++":                    "extras/notepadplusplus",
+	"obs studio":                   "extras/obs-studio",
+	"postman":                      "extras/postman",
+	"putty release":                "extras/putty",
+	"python":                       "python",
+	"sharex":                       "extras/sharex",
+	"vlc media player":             "extras/vlc",
+	"winmerge":                     "extras/winmerge",
+	"winscp":                       "extras/winscp",
+}
+
+// findScoopApps matches the unmatched programs against the built-in app list
+// and adds what it finds to matches
+func findScoopApps(unmatched []Program, matches map[string]string) {
+	for _, program := range unmatched {
+		app, ok := scoopApps[strings.ToLower(program.Name)]
+		if !ok {
+			app, ok = scoopApps[strings.ToLower(cleanProgramName(program.Name))]
+		}
+		if ok {
+			matches[program.Name] = app
+		}
+	}
+}
+
+// scoopAppList is the file written by "export --format scoop"
+type scoopAppList struct {
+	Apps []string `json:"apps"`
+}
+
+// saveScoopApps writes the matched apps as {"apps": [...]}
+// Several registry entries can map to one app, so each app is written once
+func saveScoopApps(programs []Program, matches map[string]string, filename string) error {
+	list := scoopAppList{Apps: []string{}}
+	written := make(map[string]bool)
+	for _, program := range programs {
+		app, ok := matches[program.Name]
+		if !ok || written[strings.ToLower(app)] {
+			continue
+		}
+		written[strings.ToLower(app)] = true
+		list.Apps = append(list.Apps, app)
+	}
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err = encoder.Encode(list)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	return file.Commit()
+}
+
+// saveUnmapped writes the programs without a package to a text file, one per line
+func saveUnmapped(programs []Program, matches map[string]string, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	for _, program := range programs {
+		if _, ok := matches[program.Name]; !ok {
+			fmt.Fprintf(file, "%s\n", programLabel(program))
+		}
+	}
+	return file.Commit()
+}
+
+// xmlAttr escapes a value for use inside a double-quoted XML attribute
+func xmlAttr(value string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	// Add the --format flag to choose the package manager
+	exportCmd.Flags().String("format", "winget", "Package manager file to write (winget, chocolatey, scoop)")
+
+	exportCmd.Flags().StringP("output", "o", "", "File to write (default depends on --format; - for stdout)")
+
+	// Add the --winget-map-file flag for user-supplied package IDs
+	exportCmd.Flags().String("winget-map-file", "", "JSON file mapping DisplayName to winget package ID, used before the winget lookup")
+
+	// Add the --choco-map-file flag for user-supplied Chocolatey IDs
+	exportCmd.Flags().String("choco-map-file", "", "JSON file mapping DisplayName to Chocolatey package ID, used before the lookup")
+
+	// Add the --scoop-map-file flag for user-supplied Scoop app names
+	exportCmd.Flags().String("scoop-map-file", "", "JSON file mapping DisplayName to Scoop app, used before the built-in list")
+
+	// Add the --unmapped-output flag to keep the programs that need a manual install
+	exportCmd.Flags().String("unmapped-output", "", "Write the programs without a package to this text file instead of listing them")
+
+	// Add the --dry-run flag to review the file before it's written
+	exportCmd.Flags().Bool("dry-run", false, "Print the file to stdout instead of writing it")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(exportCmd)
+}
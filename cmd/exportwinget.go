@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// exportWingetCmd represents the export-winget command
+var exportWingetCmd = &cobra.Command{
+	Use:   "export-winget",
+	Short: "Generate a winget install script for the installed programs",
+	Long: `Scan the installed programs and write a script that reinstalls them
+with winget (the Windows Package Manager) on another machine.
+
+The registry doesn't store winget package IDs, so each program's name is
+looked up with "winget search". Programs without a matching package are
+written to the script as comments so you can handle them by hand.
+
+The script type follows the output file extension:
+- PowerShell (.ps1, default): comments start with #
+- Batch file (.bat or .cmd): comments start with REM
+
+--dry-run prints the script to stdout instead of writing it, and names the
+file it would have written, so it can be reviewed first.
+
+Examples:
+  winclone export-winget                         # Writes winget-install.ps1
+  winclone export-winget -o setup.bat            # Writes a batch file
+  winclone export-winget --dry-run               # Print the script instead of writing it`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone export-winget"
+		outputFile, _ := cmd.Flags().GetString("output")
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+
+		// winget has to be installed for the lookups to work
+		_, err := exec.LookPath("winget")
+		if err != nil {
+			return fmt.Errorf("winget was not found. Install \"App Installer\" from the Microsoft Store first")
+		}
+
+		fmt.Fprintln(os.Stderr, "WinClone - Scanning installed programs...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		// Look up every program in winget (this is the slow part)
+		fmt.Fprintf(os.Stderr, "\nLooking up %d programs in winget...\n", len(programs))
+		matches := findWingetIDs(programs)
+
+		// A dry run prints the script to stdout instead, and says where it would have gone
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			writeWingetScript(os.Stdout, programs, matches, scriptComment(outputFile))
+			fmt.Fprintf(os.Stderr, "\nMatched %d of %d programs to winget packages\n", len(matches), len(programs))
+			fmt.Fprintf(os.Stderr, "Dry run: nothing was written. Without --dry-run this would be saved to: %s\n", outputFile)
+			return nil
+		}
+
+		err = saveWingetScript(programs, matches, outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to save script: %v", err)
+		}
+
+		fmt.Printf("\nMatched %d of %d programs to winget packages\n", len(matches), len(programs))
+		fmt.Printf("Script saved to: %s\n", outputFile)
+
+		return nil
+	},
+}
+
+// findWingetIDs looks up a winget package ID for each program
+// The result maps program names to IDs; unmatched programs are left out
+func findWingetIDs(programs []Program) map[string]string {
+	matches := make(map[string]string)
+	searched := make(map[string]string) // Cleaned name -> ID, so we search each name once
+
+	tracker := newProgressTracker(len(programs))
+	for i, program := range programs {
+		if i%10 == 0 && i > 0 {
+			tracker.update(i)
+			fmt.Fprintf(os.Stderr, "  Looked up %d/%d programs...%s\n", i, len(programs), tracker.status())
+		}
+
+		name := cleanProgramName(program.Name)
+		id, done := searched[name]
+		if !done {
+			id = searchWinget(name)
+			searched[name] = id
+		}
+		if id != "" {
+			matches[program.Name] = id
+		}
+	}
+
+	return matches
+}
+
+// versionSuffix matches trailing version numbers like " 2.47.1" or " v8.04"
+var versionSuffix = regexp.MustCompile(`\s+v?\d+(\.\d+)+\s*$`)
+
+// parenthesized matches parenthesized notes like " (64-bit)" or " (x64 en-US)"
+var parenthesized = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// cleanProgramName strips version numbers and architecture notes from a
+// registry DisplayName, so "Mozilla Firefox (x64 en-US)" becomes "Mozilla Firefox"
+func cleanProgramName(name string) string {
+	name = parenthesized.ReplaceAllString(name, "")
+	name = versionSuffix.ReplaceAllString(name, "")
+	return strings.TrimSpace(name)
+}
+
+// searchWinget asks winget for a package with the given name
+// It returns the package ID, or "" when there is no clear match
+func searchWinget(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	output, err := exec.Command("winget", "search", "--name", name, "--accept-source-agreements").Output()
+	if err != nil {
+		return "" // winget exits non-zero when nothing is found
+	}
+
+	results := parseWingetTable(string(output))
+
+	// Prefer an exact (case-insensitive) name match
+	for _, result := range results {
+		if strings.EqualFold(result.Name, name) {
+			return result.ID
+		}
+	}
+
+	// Otherwise only trust the result if it's the only one
+	if len(results) == 1 {
+		return results[0].ID
+	}
+	return ""
+}
+
+// wingetPackage is one row of "winget search" output
+type wingetPackage struct {
+	Name string
+	ID   string
+}
+
+// parseWingetTable reads the Name and Id columns from winget's table output
+// winget prints a header line, a line of dashes, then one row per package;
+// column positions are taken from the header
+func parseWingetTable(output string) []wingetPackage {
+	var packages []wingetPackage
+
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	idStart, idEnd := -1, -1
+	inRows := false
+
+	for _, line := range lines {
+		// winget draws a progress spinner with carriage returns; keep the final text
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+
+		// Step 1: Find the header and work out where the Id column is
+		if idStart < 0 {
+			idIndex := strings.Index(line, " Id ")
+			if strings.HasPrefix(strings.TrimSpace(line), "Name") && idIndex > 0 {
+				idStart = utf8.RuneCountInString(line[:idIndex+1])
+				if versionIndex := strings.Index(line, " Version"); versionIndex > idIndex {
+					idEnd = utf8.RuneCountInString(line[:versionIndex+1])
+				}
+			}
+			continue
+		}
+
+		// Step 2: Skip the line of dashes under the header
+		if !inRows {
+			inRows = strings.HasPrefix(line, "-")
+			continue
+		}
+
+		// Step 3: Cut each row at the column positions (names may contain spaces)
+		runes := []rune(line)
+		if len(runes) <= idStart {
+			continue
+		}
+		end := len(runes)
+		if idEnd > idStart && idEnd < end {
+			end = idEnd
+		}
+
+		name := strings.TrimSpace(string(runes[:idStart]))
+		id := strings.TrimSpace(string(runes[idStart:end]))
+		if name != "" && id != "" {
+			packages = append(packages, wingetPackage{Name: name, ID: id})
+		}
+	}
+
+	return packages
+}
+
+// saveWingetScript writes a PowerShell or batch script of winget install commands
+// Programs without a match are written as comments so nothing is silently dropped
+func saveWingetScript(programs []Program, matches map[string]string, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	writeWingetScript(file, programs, matches, scriptComment(filename))
+	return file.Commit()
+}
+
+// scriptComment picks the comment style from the file extension:
+// REM for batch files, # for PowerShell
+func scriptComment(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".bat" || ext == ".cmd" {
+		return "REM"
+	}
+	return "#"
+}
+
+// writeWingetScript writes the script to any writer, with the given comment style
+func writeWingetScript(file io.Writer, programs []Program, matches map[string]string, comment string) {
+	// Write header
+	if comment == "REM" {
+		fmt.Fprintf(file, "@echo off\n")
+	}
+	fmt.Fprintf(file, "%s WinClone - winget install script\n", comment)
+	fmt.Fprintf(file, "%s Matched %d of %d programs\n\n", comment, len(matches), len(programs))
+
+	// Write the install commands first, then the programs we couldn't match
+	// Several registry entries can map to one package, so each ID is written once
+	written := make(map[string]bool)
+	for _, program := range programs {
+		id, ok := matches[program.Name]
+		if !ok || written[id] {
+			continue
+		}
+		written[id] = true
+		fmt.Fprintf(file, "winget install --id %s -e --accept-package-agreements --accept-source-agreements\n", id)
+	}
+
+	fmt.Fprintf(file, "\n%s Programs without a winget package (install these manually):\n", comment)
+	for _, program := range programs {
+		if _, ok := matches[program.Name]; !ok {
+			fmt.Fprintf(file, "%s   %s\n", comment, programLabel(program))
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportWingetCmd)
+
+	exportWingetCmd.Flags().StringP("output", "o", "winget-install.ps1", "Script file to write (.ps1 for PowerShell, .bat for batch)")
+
+	// Add the --dry-run flag to review the script before it's written
+	exportWingetCmd.Flags().Bool("dry-run", false, "Print the script to stdout instead of writing it")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(exportWingetCmd)
+}
@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// filterByText keeps programs whose Name or Publisher contains the given text
+// The match is case-insensitive, so "python" matches "Python 3.12.1 (64-bit)"
+func filterByText(programs []Program, text string) []Program {
+	text = strings.ToLower(text)
+
+	var filtered []Program
+	for _, program := range programs {
+		if strings.Contains(strings.ToLower(program.Name), text) ||
+			strings.Contains(strings.ToLower(program.Publisher), text) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered
+}
+
+// filterByName keeps programs whose Name contains the given text (case-insensitive)
+func filterByName(programs []Program, text string) []Program {
+	text = strings.ToLower(text)
+
+	var filtered []Program
+	for _, program := range programs {
+		if strings.Contains(strings.ToLower(program.Name), text) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered
+}
+
+// hideSystemComponents drops entries marked SystemComponent=1
+// These are runtimes, drivers and installer pieces that Windows itself hides
+// from "Add or Remove Programs". It also returns how many were hidden
+func hideSystemComponents(programs []Program) ([]Program, int) {
+	var visible []Program
+	for _, program := range programs {
+		if !program.SystemComponent {
+			visible = append(visible, program)
+		}
+	}
+	return visible, len(programs) - len(visible)
+}
+
+// windowsUpdatePrefixes are how Windows and Office patches name their Uninstall entries
+var windowsUpdatePrefixes = []string{
+	"security update for ",
+	"update for ",
+	"hotfix for ",
+	"critical update for ",
+	"cumulative update for ",
+	"definition update for ",
+	"service pack ",
+}
+
+// kbNumberPattern matches a Knowledge Base article number like "KB5034441",
+// either as the whole name or in parentheses after it
+var kbNumberPattern = regexp.MustCompile(`(?i)^kb\d{6,7}$|\(kb\d{6,7}\)`)
+
+// isWindowsUpdate reports whether a program name looks like a patch rather than
+// software, e.g. "Security Update for Microsoft Office 2016 (KB5002467) 64-Bit Edition"
+// It's a name heuristic, so it errs on the side of keeping real programs
+func isWindowsUpdate(name string) bool {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, prefix := range windowsUpdatePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return kbNumberPattern.MatchString(lower)
+}
+
+// hideWindowsUpdates drops the entries isWindowsUpdate recognises as patches
+// It also returns how many were hidden
+func hideWindowsUpdates(programs []Program) ([]Program, int) {
+	var visible []Program
+	for _, program := range programs {
+		if !isWindowsUpdate(program.Name) {
+			visible = append(visible, program)
+		}
+	}
+	return visible, len(programs) - len(visible)
+}
+
+// addIncludeUpdatesFlag adds --include-updates to a command that lists scanned programs
+func addIncludeUpdatesFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("include-updates", false, "Include Windows and Office update entries (\"Update for ...\", \"(KB1234567)\")")
+}
+
+// visiblePrograms trims a scan down to what the scan command lists by default:
+// no system components, no duplicates and, unless includeUpdates, no Windows updates
+func visiblePrograms(programs []Program, includeUpdates bool) []Program {
+	programs, _ = hideSystemComponents(programs)
+	if !includeUpdates {
+		programs, _ = hideWindowsUpdates(programs)
+	}
+	programs, _ = dedupPrograms(programs)
+	return programs
+}
+
+// filterByExactName keeps programs whose whole Name equals the given text
+// The match ignores case and surrounding whitespace, so "git" matches "Git"
+func filterByExactName(programs []Program, text string) []Program {
+	text = strings.TrimSpace(text)
+
+	var filtered []Program
+	for _, program := range programs {
+		if strings.EqualFold(program.Name, text) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered
+}
+
+// filterByRegex keeps programs whose Name matches the regular expression
+// An invalid pattern is reported as a normal error rather than a panic
+func filterByRegex(programs []Program, pattern string) ([]Program, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --filter-regex pattern %q: %v", pattern, err)
+	}
+
+	var filtered []Program
+	for _, program := range programs {
+		if re.MatchString(program.Name) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered, nil
+}
+
+// filterByPublisher keeps programs whose Publisher contains the given text
+// The match is case-insensitive, so "microsoft" matches "Microsoft Corporation"
+func filterByPublisher(programs []Program, publisher string) []Program {
+	publisher = strings.ToLower(publisher)
+
+	var filtered []Program
+	for _, program := range programs {
+		if strings.Contains(strings.ToLower(program.Publisher), publisher) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered
+}
+
+// filterByArchitecture keeps programs of one architecture ("x64" or "x86")
+// "64" and "32" are accepted as friendlier spellings
+func filterByArchitecture(programs []Program, arch string) ([]Program, error) {
+	switch strings.ToLower(arch) {
+	case "x64", "64":
+		arch = "x64"
+	case "x86", "32":
+		arch = "x86"
+	default:
+		return nil, fmt.Errorf("unknown architecture %q (valid values: x64, x86)", arch)
+	}
+
+	var filtered []Program
+	for _, program := range programs {
+		if program.Architecture == arch {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered, nil
+}
+
+// Scope values stored on Program and accepted by --scope
+const (
+	scopeMachine = "machine" // Installed for everyone (HKLM)
+	scopeUser    = "user"    // Installed just for the current user (HKCU)
+	scopeAll     = "all"     // --scope only: both of the above
+)
+
+// validateScope checks a --scope value before the scan starts
+func validateScope(scope string) error {
+	switch scope {
+	case scopeMachine, scopeUser, scopeAll:
+		return nil
+	}
+	return fmt.Errorf("unknown scope %q (valid values: machine, user, all)", scope)
+}
+
+// filterByScope keeps machine-wide or per-user programs ("all" keeps everything)
+func filterByScope(programs []Program, scope string) []Program {
+	if scope == scopeAll {
+		return programs
+	}
+
+	var filtered []Program
+	for _, program := range programs {
+		if program.Scope == scope {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered
+}
+
+// excludeByName drops programs whose Name matches any of the patterns
+// Patterns are case-insensitive substrings, or regular expressions when they
+// start with "re:", e.g. "re:^Microsoft Visual C\+\+ 20\d\d". It also returns
+// how many programs were dropped
+func excludeByName(programs []Program, patterns []string) ([]Program, int, error) {
+	// Compile every pattern up front so a bad regex fails before anything is dropped
+	var substrings []string
+	var regexes []*regexp.Regexp
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+			re, err := regexp.Compile("(?i)" + expr)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid --exclude pattern %q: %v", pattern, err)
+			}
+			regexes = append(regexes, re)
+		} else {
+			substrings = append(substrings, strings.ToLower(pattern))
+		}
+	}
+
+	var kept []Program
+	for _, program := range programs {
+		if !nameMatchesAny(program.Name, substrings, regexes) {
+			kept = append(kept, program)
+		}
+	}
+	return kept, len(programs) - len(kept), nil
+}
+
+// nameMatchesAny reports whether name contains any of the (lowercase) substrings
+// or matches any of the regular expressions
+func nameMatchesAny(name string, substrings []string, regexes []*regexp.Regexp) bool {
+	lower := strings.ToLower(name)
+	for _, substring := range substrings {
+		if strings.Contains(lower, substring) {
+			return true
+		}
+	}
+	for _, re := range regexes {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeUnits are the suffixes --min-size accepts, in kilobytes
+// EstimatedSize is stored in KB, so sizes are compared in KB too
+var sizeUnits = []struct {
+	suffix string
+	kb     float64
+}{
+	{"gb", 1024 * 1024},
+	{"mb", 1024},
+	{"kb", 1},
+}
+
+// parseSize turns a human-friendly size like "500MB", "2GB" or "1.5 gb" into kilobytes
+// The unit is required and case-insensitive
+func parseSize(value string) (uint64, error) {
+	text := strings.ToLower(strings.TrimSpace(value))
+	for _, unit := range sizeUnits {
+		number, ok := strings.CutSuffix(text, unit.suffix)
+		if !ok {
+			continue
+		}
+		// ParseFloat also accepts "NaN" and "Inf", which aren't sizes, and the
+		// product must fit in a uint64 or the conversion gives nonsense
+		size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || size < 0 || math.IsNaN(size) || math.IsInf(size, 0) || size*unit.kb >= math.MaxUint64 {
+			break
+		}
+		return uint64(size * unit.kb), nil
+	}
+	return 0, fmt.Errorf("invalid size %q (use a number with KB, MB or GB, e.g. 500MB or 2GB)", value)
+}
+
+// filterByMinSize keeps programs whose EstimatedSize is at least minKB
+// Programs that don't record a size can't be compared, so they're dropped too;
+// it also returns how many of those there were
+func filterByMinSize(programs []Program, minKB uint64) ([]Program, int) {
+	var filtered []Program
+	noSize := 0
+	for _, program := range programs {
+		if program.SizeKB == 0 {
+			noSize++
+			continue
+		}
+		if program.SizeKB >= minKB {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered, noSize
+}
+
+// parseSince turns a --modified-since value into the first day to include
+// It accepts a date like "2024-01-14" or a number of days back like "7d"
+// InstallDate only records the day (as midnight UTC), so the result is a UTC midnight too
+func parseSince(value string, now time.Time) (time.Time, error) {
+	text := strings.ToLower(strings.TrimSpace(value))
+
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			year, month, day := now.AddDate(0, 0, -n).Date()
+			return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	date, err := time.Parse("2006-01-02", text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use a date like 2024-01-14 or a number of days like 7d)", value)
+	}
+	return date, nil
+}
+
+// filterByInstalledSince keeps programs whose InstallDate is on or after since
+// Programs without a readable InstallDate can't be placed in time, so they're
+// dropped too; it also returns how many of those there were
+func filterByInstalledSince(programs []Program, since time.Time) ([]Program, int) {
+	var filtered []Program
+	noDate := 0
+	for _, program := range programs {
+		if program.InstallDate == nil {
+			noDate++
+			continue
+		}
+		if !program.InstallDate.Before(since) {
+			filtered = append(filtered, program)
+		}
+	}
+	return filtered, noDate
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsWindowsUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Security Update for Microsoft Office 2016 (KB5002467) 64-Bit Edition", true},
+		{"Update for Microsoft Office 2013 (KB4022166) 32-Bit Edition", true},
+		{"Hotfix for Microsoft .NET Framework 4.8 (KB4486153)", true},
+		{"Cumulative Update for Windows 10 Version 22H2", true},
+		{"Service Pack 1 for Microsoft Office 2013", true},
+		{"KB5034441", true},
+		{"  update for windows  ", true},
+		{"Microsoft Visual C++ 2015-2022 Redistributable (x64) - 14.38.33135", false},
+		{"Microsoft Update Health Tools", false},
+		{"Windows PC Health Check", false},
+		{"Notepad++ (64-bit x64)", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		got := isWindowsUpdate(tt.name)
+		if got != tt.want {
+			t.Errorf("isWindowsUpdate(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 15, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2024-01-14", want: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{value: "7d", want: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{value: " 30D ", want: time.Date(2024, 2, 14, 0, 0, 0, 0, time.UTC)},
+		{value: "0d", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "-7d", wantErr: true},
+		{value: "last week", wantErr: true},
+		{value: "14/01/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSince(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSince(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    uint64
+		wantErr bool
+	}{
+		{value: "500KB", want: 500},
+		{value: "500MB", want: 500 * 1024},
+		{value: "2GB", want: 2 * 1024 * 1024},
+		{value: " 1.5 gb ", want: 1536 * 1024},
+		{value: "0MB", want: 0},
+		{value: "500", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "lotsMB", wantErr: true},
+		{value: "NaNMB", wantErr: true},
+		{value: "nan gb", wantErr: true},
+		{value: "InfGB", wantErr: true},
+		{value: "+Inf kb", wantErr: true},
+		{value: "1e30GB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSize(%q) = %d, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSize(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
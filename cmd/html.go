@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// htmlReport is the data passed to the HTML report template
+type htmlReport struct {
+	Timestamp string
+	Count     int
+	TotalSize string
+	Programs  []htmlRow
+}
+
+// htmlRow is one program, with its values already formatted for display
+// SizeKB is kept as a number so the size column sorts numerically
+type htmlRow struct {
+	Program
+	Size      string
+	Installed string
+}
+
+// htmlTemplate is the whole report: styles and script are inlined so the file
+// works offline and can be emailed or attached to a ticket as-is
+// html/template escapes every program value, so odd registry data can't break the page
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>WinClone Report</title>
+<style>
+  body { font-family: Segoe UI, Arial, sans-serif; margin: 2em; color: #222; }
+  .summary { display: inline-block; border: 1px solid #ccc; border-radius: 6px; padding: 1em 2em; margin-bottom: 1em; }
+  .summary div { margin: 0.2em 0; }
+  #search { width: 30em; padding: 0.4em; margin-bottom: 1em; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { border: 1px solid #ddd; padding: 0.3em 0.6em; text-align: left; }
+  th { background: #f0f0f0; cursor: pointer; user-select: none; }
+  th[aria-sort="ascending"]::after { content: " \25B2"; }
+  th[aria-sort="descending"]::after { content: " \25BC"; }
+  tr:nth-child(even) { background: #fafafa; }
+</style>
+</head>
+<body>
+<h1>WinClone Report</h1>
+<div class="summary">
+  <div><strong>Programs:</strong> {{.Count}}</div>
+  <div><strong>Total size:</strong> {{.TotalSize}}</div>
+  <div><strong>Scanned:</strong> {{.Timestamp}}</div>
+</div>
+<div><input id="search" type="text" placeholder="Filter by any field..."></div>
+<table id="programs">
+<thead>
+<tr>
+  <th>Name</th><th>Version</th><th>Publisher</th><th>Path</th><th>Architecture</th>
+  <th>Source</th><th>Installed</th><th data-type="number">Size</th>
+</tr>
+</thead>
+<tbody>
+{{- range .Programs}}
+<tr>
+  <td>{{.Name}}</td><td>{{.Version}}</td><td>{{.Publisher}}</td><td>{{.Path}}</td><td>{{.Architecture}}</td>
+  <td>{{.Source}}</td><td>{{.Installed}}</td><td data-value="{{.SizeKB}}">{{.Size}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+// Hide rows that don't contain the search text
+document.getElementById("search").addEventListener("input", function () {
+  var text = this.value.toLowerCase();
+  document.querySelectorAll("#programs tbody tr").forEach(function (row) {
+    row.style.display = row.textContent.toLowerCase().indexOf(text) >= 0 ? "" : "none";
+  });
+});
+
+// Sort by a column when its header is clicked; click again to reverse
+// aria-sort marks the sorted column, and the CSS above draws an arrow for it
+var headers = document.querySelectorAll("#programs th");
+headers.forEach(function (header, column) {
+  var ascending = true;
+  header.addEventListener("click", function () {
+    headers.forEach(function (other) { other.removeAttribute("aria-sort"); });
+    header.setAttribute("aria-sort", ascending ? "ascending" : "descending");
+    var numeric = header.dataset.type === "number";
+    var body = document.querySelector("#programs tbody");
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[column], y = b.cells[column];
+      var result = numeric
+        ? Number(x.dataset.value) - Number(y.dataset.value)
+        : x.textContent.localeCompare(y.textContent, undefined, { numeric: true, sensitivity: "base" });
+      return ascending ? result : -result;
+    });
+    ascending = !ascending;
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// saveToHTML saves the program list as a single-file HTML report
+// The report has a summary, a search box and a sortable table, and needs no
+// internet connection, so it can be shared with people who don't use the command line
+func saveToHTML(programs []Program, filename string) error {
+	// Step 1: Build the report data
+	report := htmlReport{
+		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Count:     len(programs),
+	}
+	for _, program := range programs {
+		row := htmlRow{Program: program, Installed: installedLabel(program)}
+		if program.SizeKB > 0 {
+			row.Size = formatSize(program.SizeKB, false) // Leave unknown sizes blank
+		}
+		report.Programs = append(report.Programs, row)
+	}
+	report.TotalSize = formatSize(totalSizeKB(programs), false)
+
+	// Step 2: Create the HTML file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	// Step 3: Fill in the template
+	err = htmlTemplate.Execute(file, report)
+	if err != nil {
+		return fmt.Errorf("failed to write HTML: %v", err)
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/sys/windows"
+)
+
+// selectModel is the checkbox list shown by "scan --interactive"
+// Typing filters the list; the selection is kept per program, so it survives
+// changing the filter
+type selectModel struct {
+	programs []Program
+	selected []bool // Indexed like programs
+	filter   string
+	visible  []int // Indexes into programs that match the filter
+	cursor   int   // Position in visible
+	offset   int   // First visible row on screen, for scrolling
+	height   int   // Terminal height, from tea.WindowSizeMsg
+	done     bool  // Enter was pressed
+}
+
+// newSelectModel starts with every program selected, since most clone
+// scripts keep most programs and only drop a few
+func newSelectModel(programs []Program) *selectModel {
+	m := &selectModel{programs: programs, selected: make([]bool, len(programs)), height: 24}
+	for i := range m.selected {
+		m.selected[i] = true
+	}
+	m.applyFilter()
+	return m
+}
+
+// applyFilter rebuilds the visible rows from the filter text
+func (m *selectModel) applyFilter() {
+	m.visible = m.visible[:0]
+	needle := strings.ToLower(m.filter)
+	for i, program := range m.programs {
+		if strings.Contains(strings.ToLower(program.Name), needle) ||
+			strings.Contains(strings.ToLower(program.Publisher), needle) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+// Init has nothing to start; the list is already loaded
+func (m *selectModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles key presses and terminal resizes
+func (m *selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyEnter:
+			m.done = true
+			return m, tea.Quit
+		case tea.KeyUp:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case tea.KeyDown:
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+		case tea.KeyPgUp:
+			m.cursor = max(m.cursor-m.listHeight(), 0)
+		case tea.KeyPgDown:
+			m.cursor = max(min(m.cursor+m.listHeight(), len(m.visible)-1), 0)
+		case tea.KeySpace:
+			// Names have spaces, so while filtering a space is part of the filter
+			if m.filter != "" {
+				m.filter += " "
+				m.applyFilter()
+			} else {
+				m.toggle()
+			}
+		case tea.KeyTab:
+			m.toggle()
+		case tea.KeyCtrlA:
+			// Select all visible rows, or clear them if they're all selected already
+			all := true
+			for _, i := range m.visible {
+				all = all && m.selected[i]
+			}
+			for _, i := range m.visible {
+				m.selected[i] = !all
+			}
+		case tea.KeyBackspace:
+			if m.filter != "" {
+				runes := []rune(m.filter)
+				m.filter = string(runes[:len(runes)-1])
+				m.applyFilter()
+			}
+		case tea.KeyRunes:
+			m.filter += string(msg.Runes)
+			m.applyFilter()
+		}
+	}
+
+	// Scroll so the cursor stays on screen
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.listHeight() {
+		m.offset = m.cursor - m.listHeight() + 1
+	}
+	return m, nil
+}
+
+// toggle ticks or unticks the program under the cursor
+func (m *selectModel) toggle() {
+	if len(m.visible) > 0 {
+		i := m.visible[m.cursor]
+		m.selected[i] = !m.selected[i]
+	}
+}
+
+// listHeight is how many program rows fit under the header and above the footer
+func (m *selectModel) listHeight() int {
+	return max(m.height-5, 1)
+}
+
+// View draws the header, the visible part of the list and the footer
+func (m *selectModel) View() string {
+	var b strings.Builder
+	b.WriteString("Select programs: Tab (or Space) toggles, Ctrl+A selects all, Enter saves, Esc cancels\n")
+	fmt.Fprintf(&b, "Filter: %s_\n\n", m.filter)
+
+	end := min(m.offset+m.listHeight(), len(m.visible))
+	for row := m.offset; row < end; row++ {
+		i := m.visible[row]
+		cursor, check := "  ", "[ ]"
+		if row == m.cursor {
+			cursor = "> "
+		}
+		if m.selected[i] {
+			check = "[x]"
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, check, programLabel(m.programs[i]))
+	}
+	if len(m.visible) == 0 {
+		b.WriteString("  No programs match the filter\n")
+	}
+
+	fmt.Fprintf(&b, "\n%d of %d programs selected", m.selectedCount(), len(m.programs))
+	return b.String()
+}
+
+// selectedCount is the number of programs ticked, filtered out or not
+func (m *selectModel) selectedCount() int {
+	count := 0
+	for _, selected := range m.selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+// selectPrograms shows the checkbox list and returns the programs that were ticked
+// ok is false when the user cancelled with Esc or Ctrl+C
+func selectPrograms(programs []Program) (chosen []Program, ok bool, err error) {
+	model := newSelectModel(programs)
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, false, fmt.Errorf("interactive selection failed: %v", err)
+	}
+	if !model.done {
+		return nil, false, nil
+	}
+
+	for i, program := range programs {
+		if model.selected[i] {
+			chosen = append(chosen, program)
+		}
+	}
+	return chosen, true, nil
+}
+
+// isConsole reports whether f is an interactive console rather than a pipe or file
+func isConsole(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
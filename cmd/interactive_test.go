@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeKeys sends each message to the model in turn, like a user typing
+func typeKeys(m *selectModel, msgs ...tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+var (
+	keySpace = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	keyTab   = tea.KeyMsg{Type: tea.KeyTab}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+)
+
+func keyText(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+func newTestSelectModel() *selectModel {
+	return newSelectModel([]Program{
+		{Name: "Git"},
+		{Name: "Visual Studio Code"},
+		{Name: "Microsoft Visual C++ 2015-2022 Redistributable"},
+	})
+}
+
+func TestSelectModelSpace(t *testing.T) {
+	m := newTestSelectModel()
+
+	// With no filter, Space toggles the row under the cursor
+	typeKeys(m, keySpace)
+	if m.selected[0] {
+		t.Error("Space with an empty filter didn't untick Git")
+	}
+
+	// While filtering, Space is typed into the filter and toggles nothing
+	typeKeys(m, keyText("visual"), keySpace, keyText("s"))
+	if m.filter != "visual s" {
+		t.Errorf("filter = %q, want %q", m.filter, "visual s")
+	}
+	if len(m.visible) != 1 || m.visible[0] != 1 {
+		t.Errorf("visible = %v, want only Visual Studio Code", m.visible)
+	}
+	if m.selectedCount() != 2 {
+		t.Errorf("%d programs selected, want 2", m.selectedCount())
+	}
+}
+
+func TestSelectModelTab(t *testing.T) {
+	m := newTestSelectModel()
+
+	// Tab toggles while filtering, and the selection survives clearing the filter
+	typeKeys(m, keyText("visual"), keyDown, keyTab)
+	if m.selected[2] || !m.selected[1] {
+		t.Errorf("selected = %v, want only the C++ redistributable unticked", m.selected)
+	}
+
+	typeKeys(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.filter != "visua" {
+		t.Errorf("filter = %q after Backspace, want %q", m.filter, "visua")
+	}
+	for range "visua" {
+		typeKeys(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	if len(m.visible) != 3 || m.selected[2] {
+		t.Errorf("visible = %v, selected = %v after clearing the filter", m.visible, m.selected)
+	}
+}
+
+func TestSelectModelSelectAll(t *testing.T) {
+	m := newTestSelectModel()
+
+	// Everything starts selected, so Ctrl+A clears the visible rows, then selects them again
+	typeKeys(m, keyText("visual"), tea.KeyMsg{Type: tea.KeyCtrlA})
+	if m.selectedCount() != 1 || !m.selected[0] {
+		t.Errorf("selected = %v, want only Git after clearing the visible rows", m.selected)
+	}
+	typeKeys(m, tea.KeyMsg{Type: tea.KeyCtrlA})
+	if m.selectedCount() != 3 {
+		t.Errorf("%d programs selected, want 3", m.selectedCount())
+	}
+}
+
+func TestSelectModelEnterAndEsc(t *testing.T) {
+	m := newTestSelectModel()
+	cmd := typeKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.done || cmd == nil {
+		t.Error("Enter should finish the selection and quit")
+	}
+
+	m = newTestSelectModel()
+	cmd = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.done || cmd == nil {
+		t.Error("Esc should quit without finishing the selection")
+	}
+}
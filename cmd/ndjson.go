@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// saveToNDJSON saves the program list as NDJSON (JSON Lines): one program
+// object per line, which jq and log shippers read without loading the whole file
+func saveToNDJSON(programs []Program, filename string) error {
+	// Create the NDJSON file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	err = writeNDJSON(file, programs)
+	if err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// writeNDJSON writes each program as a compact JSON object on its own line
+func writeNDJSON(w io.Writer, programs []Program) error {
+	for _, program := range programs {
+		err := writeNDJSONLine(w, program)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeNDJSONLine writes one program as a line of NDJSON
+func writeNDJSONLine(w io.Writer, program Program) error {
+	line, err := json.Marshal(program)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %v", err)
+	}
+	line = append(line, '\n')
+	_, err = w.Write(line)
+	if err != nil {
+		return fmt.Errorf("failed to write NDJSON: %v", err)
+	}
+	return nil
+}
+
+// streamScan prints each program to stdout as NDJSON the moment it's read,
+// instead of waiting for the whole scan like the other formats
+// Merging duplicates, filtering and sorting need the full list, so they don't
+// apply; system components and Windows updates are still left out unless asked for
+// Entries that couldn't be read are counted on stderr, where they don't mix with the NDJSON
+func streamScan(scanner *Scanner, includeSystem, includeUpdates bool) error {
+	var writeErr error
+	scanner.OnProgram = func(program Program) {
+		if writeErr != nil {
+			return // stdout is gone (e.g. the pipe was closed), so stop writing
+		}
+		if (program.SystemComponent && !includeSystem) || (isWindowsUpdate(program.Name) && !includeUpdates) {
+			return
+		}
+		writeErr = writeNDJSONLine(os.Stdout, program)
+	}
+
+	_, skipped, err := scanner.scanAllPrograms()
+	if err != nil {
+		return fmt.Errorf("failed to scan programs: %v", err)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d registry entries couldn't be read and were left out (run without --stream and with --show-errors for details)\n", len(skipped))
+	}
+	return writeErr
+}
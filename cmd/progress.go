@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// progressWindow is how many recent samples are used for the rolling rate
+const progressWindow = 10
+
+// minETASpan is how much time the samples must cover before we trust an ETA
+// Estimates based on a few milliseconds of work jump around too much to be useful
+const minETASpan = 500 * time.Millisecond
+
+// progressSample records how many items were done at a point in time
+type progressSample struct {
+	at   time.Time
+	done int
+}
+
+// progressTracker estimates time remaining for a long loop
+// It keeps a rolling window of samples so the rate follows recent speed,
+// not the average since the start (registry reads slow down and speed up a lot)
+type progressTracker struct {
+	total   int
+	samples []progressSample
+}
+
+// newProgressTracker starts tracking a loop over total items
+func newProgressTracker(total int) *progressTracker {
+	return &progressTracker{
+		total:   total,
+		samples: []progressSample{{at: time.Now(), done: 0}},
+	}
+}
+
+// update records that done items have been processed so far
+func (p *progressTracker) update(done int) {
+	p.samples = append(p.samples, progressSample{at: time.Now(), done: done})
+	if len(p.samples) > progressWindow {
+		p.samples = p.samples[len(p.samples)-progressWindow:]
+	}
+}
+
+// rate returns the rolling processing rate in items per second
+// The second return value is false when there isn't enough data yet
+func (p *progressTracker) rate() (float64, bool) {
+	if len(p.samples) < 2 {
+		return 0, false
+	}
+
+	first := p.samples[0]
+	last := p.samples[len(p.samples)-1]
+	span := last.at.Sub(first.at)
+	if span < minETASpan || last.done <= first.done {
+		return 0, false
+	}
+
+	return float64(last.done-first.done) / span.Seconds(), true
+}
+
+// eta returns the estimated time until all items are processed
+func (p *progressTracker) eta() (time.Duration, bool) {
+	rate, ok := p.rate()
+	if !ok {
+		return 0, false
+	}
+
+	remaining := p.total - p.samples[len(p.samples)-1].done
+	return time.Duration(float64(remaining) / rate * float64(time.Second)), true
+}
+
+// status returns a short "(12.3/s, ETA 4s)" suffix, or "" if we can't estimate yet
+func (p *progressTracker) status() string {
+	rate, ok := p.rate()
+	if !ok {
+		return ""
+	}
+	eta, _ := p.eta()
+	return fmt.Sprintf(" (%.1f/s, ETA %s)", rate, eta.Round(time.Second))
+}
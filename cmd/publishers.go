@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// listPublishersCmd represents the list-publishers command
+var listPublishersCmd = &cobra.Command{
+	Use:   "list-publishers",
+	Short: "List the software vendors on this machine",
+	Long: `Scan the installed programs and print each publisher once, sorted
+alphabetically, one per line. Programs without a publisher are left out.
+
+This answers compliance questions like "which software vendors are on this
+machine?" without reading the whole inventory.
+
+- --count: Shows how many programs each publisher has installed
+
+Examples:
+  winclone list-publishers           # One publisher per line
+  winclone list-publishers --count   # With the number of programs each`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone list-publishers"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		showCount, _ := cmd.Flags().GetBool("count")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		publishers := listPublishers(programs)
+		if len(publishers) == 0 {
+			fmt.Fprintln(os.Stderr, "No programs record a publisher")
+			return nil
+		}
+
+		for _, publisher := range publishers {
+			if showCount {
+				fmt.Printf("%4d  %s\n", publisher.Count, publisher.Publisher)
+			} else {
+				fmt.Println(publisher.Publisher)
+			}
+		}
+
+		return nil
+	},
+}
+
+// listPublishers returns each publisher once with its number of programs,
+// sorted alphabetically (ignoring case)
+func listPublishers(programs []Program) []publisherCount {
+	counts := make(map[string]int)
+	for _, program := range programs {
+		publisher := strings.TrimSpace(program.Publisher)
+		if publisher != "" {
+			counts[publisher]++
+		}
+	}
+
+	var publishers []publisherCount
+	for publisher, count := range counts {
+		publishers = append(publishers, publisherCount{Publisher: publisher, Count: count})
+	}
+	sort.Slice(publishers, func(i, j int) bool {
+		a, b := strings.ToLower(publishers[i].Publisher), strings.ToLower(publishers[j].Publisher)
+		if a != b {
+			return a < b
+		}
+		return publishers[i].Publisher < publishers[j].Publisher
+	})
+	return publishers
+}
+
+func init() {
+	rootCmd.AddCommand(listPublishersCmd)
+
+	// Add the --count flag to show how many programs each publisher has
+	listPublishersCmd.Flags().Bool("count", false, "Show how many programs each publisher has installed")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(listPublishersCmd)
+}
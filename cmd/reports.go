@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// normalizePath cleans an install path so the same directory always compares equal
+// Registry values are inconsistent: some have quotes, trailing slashes or mixed case
+func normalizePath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.Trim(path, `"`)
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(path)
+	path = strings.TrimRight(path, `\/`)
+	return strings.ToLower(path)
+}
+
+// groupByPath groups programs by their normalized install location
+// Programs without a path are skipped because they can't conflict with anything
+func groupByPath(programs []Program) map[string][]Program {
+	groups := make(map[string][]Program)
+	for _, program := range programs {
+		dir := normalizePath(program.Path)
+		if dir == "" {
+			continue
+		}
+		groups[dir] = append(groups[dir], program)
+	}
+	return groups
+}
+
+// isNestedPath reports whether child lives inside parent (both already normalized)
+func isNestedPath(parent, child string) bool {
+	return strings.HasPrefix(child, parent+`\`) || strings.HasPrefix(child, parent+"/")
+}
+
+// displayPathConflicts prints install locations that are shared by several
+// programs, or that sit inside another program's install directory
+func displayPathConflicts(programs []Program) {
+	groups := groupByPath(programs)
+
+	// Sort the directories so the report is stable between runs
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("PATH CONFLICTS\n")
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	// Step 1: Directories registered by more than one program
+	var shared []string
+	for _, dir := range dirs {
+		if len(groups[dir]) > 1 {
+			shared = append(shared, dir)
+		}
+	}
+
+	fmt.Printf("Shared install locations: %d\n", len(shared))
+	for _, dir := range shared {
+		fmt.Printf("  %s\n", groups[dir][0].Path)
+		for _, program := range groups[dir] {
+			fmt.Printf("    - %s\n", programLabel(program))
+		}
+	}
+	fmt.Println()
+
+	// Step 2: Directories nested inside another program's directory
+	nestedCount := 0
+	var lines []string
+	for _, parent := range dirs {
+		for _, child := range dirs {
+			if !isNestedPath(parent, child) {
+				continue
+			}
+			nestedCount++
+			lines = append(lines, fmt.Sprintf("  %s (%s)\n    inside %s (%s)\n",
+				groups[child][0].Path, programNames(groups[child]),
+				groups[parent][0].Path, programNames(groups[parent])))
+		}
+	}
+
+	fmt.Printf("Nested install locations: %d\n", nestedCount)
+	for _, line := range lines {
+		fmt.Print(line)
+	}
+}
+
+// programLabel returns the program name with its version, if known
+func programLabel(program Program) string {
+	if program.Version != "" {
+		return fmt.Sprintf("%s (v%s)", program.Name, program.Version)
+	}
+	return program.Name
+}
+
+// programNames joins the names of a group of programs for one-line display
+func programNames(programs []Program) string {
+	names := make([]string, len(programs))
+	for i, program := range programs {
+		names[i] = program.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// strictFailures picks the skipped entries that should fail a --strict scan
+// Entries without a DisplayName only count when includeUnnamed is set
+func strictFailures(skipped []skippedEntry, includeUnnamed bool) []skippedEntry {
+	var failures []skippedEntry
+	for _, entry := range skipped {
+		if entry.MissingName && !includeUnnamed {
+			continue
+		}
+		failures = append(failures, entry)
+	}
+	return failures
+}
+
+// displaySkipped prints a detailed report of skipped registry entries
+func displaySkipped(skipped []skippedEntry) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("SCAN INCOMPLETE: %d entries could not be read\n", len(skipped))
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	for _, entry := range skipped {
+		if entry.Subkey == "" {
+			fmt.Printf("- %s\n", entry.Location)
+		} else {
+			fmt.Printf("- %s\\%s\n", entry.Location, entry.Subkey)
+		}
+		fmt.Printf("  Reason: %s\n", entry.Reason)
+	}
+}
+
+// displayScanErrors summarizes skipped registry entries by cause
+// Entries without a DisplayName are normal metadata, so they're only counted;
+// access-denied and other failures are listed one by one
+func displayScanErrors(w io.Writer, skipped []skippedEntry) {
+	var denied, other []skippedEntry
+	missingName := 0
+	for _, entry := range skipped {
+		switch {
+		case entry.AccessDenied:
+			denied = append(denied, entry)
+		case entry.MissingName:
+			missingName++
+		default:
+			other = append(other, entry)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "SKIPPED REGISTRY ENTRIES: %d\n", len(skipped))
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "Access denied:     %d\n", len(denied))
+	fmt.Fprintf(w, "No DisplayName:    %d (normal: updates and installer metadata)\n", missingName)
+	fmt.Fprintf(w, "Other errors:      %d\n", len(other))
+
+	writeEntries := func(title string, entries []skippedEntry) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s:\n", title)
+		for _, entry := range entries {
+			if entry.Subkey == "" {
+				fmt.Fprintf(w, "- %s\n", entry.Location)
+			} else {
+				fmt.Fprintf(w, "- %s\\%s\n", entry.Location, entry.Subkey)
+			}
+			fmt.Fprintf(w, "  Reason: %s\n", entry.Reason)
+		}
+	}
+	writeEntries("Access denied", denied)
+	writeEntries("Other errors", other)
+
+	if len(denied) > 0 {
+		fmt.Fprintf(w, "\nSome entries need more rights to read. Run WinClone from an\n")
+		fmt.Fprintf(w, "Administrator prompt to include them.\n")
+	}
+}
+
+// displayTiming prints how long each phase of the scan took
+// Reading values is wall time across all workers, so it shows what --workers buys
+func displayTiming(w io.Writer, timing scanTiming, workers int) {
+	fmt.Fprintf(w, "\n%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "SCAN TIMING\n")
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 50))
+	fmt.Fprintf(w, "Opening keys:      %v\n", timing.OpenKeys.Round(time.Microsecond))
+	fmt.Fprintf(w, "Reading subkeys:   %v\n", timing.SubkeyNames.Round(time.Microsecond))
+	fmt.Fprintf(w, "Reading values:    %v (%d workers)\n", timing.ReadValues.Round(time.Microsecond), workers)
+	if timing.Store > 0 {
+		fmt.Fprintf(w, "Store packages:    %v\n", timing.Store.Round(time.Microsecond))
+	}
+	fmt.Fprintf(w, "Total:             %v\n", timing.Total.Round(time.Microsecond))
+}
+
+// isArchMismatch reports whether a program's install path belongs to the other
+// architecture, e.g. a 64-bit registry entry installed into "Program Files (x86)"
+// This usually points at a packaging quirk rather than a real problem
+func isArchMismatch(program Program) bool {
+	dir := normalizePath(program.Path)
+	if dir == "" {
+		return false
+	}
+	dir += `\` // So "C:\Program Files" matches the same as "C:\Program Files\App"
+
+	inX86 := strings.Contains(dir, `\program files (x86)\`)
+	inX64 := strings.Contains(dir, `\program files\`)
+
+	switch program.Architecture {
+	case "x64":
+		return inX86
+	case "x86":
+		return inX64
+	}
+	return false
+}
+
+// displayArchMismatches prints programs flagged with ArchMismatch
+func displayArchMismatches(programs []Program) {
+	var mismatched []Program
+	for _, program := range programs {
+		if program.ArchMismatch {
+			mismatched = append(mismatched, program)
+		}
+	}
+
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("ARCHITECTURE MISMATCHES\n")
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	fmt.Printf("Programs installed outside their architecture's Program Files: %d\n", len(mismatched))
+	for _, program := range mismatched {
+		fmt.Printf("  %s [%s registry]\n", programLabel(program), program.Architecture)
+		fmt.Printf("    Path: %s\n", program.Path)
+	}
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <scan-file>",
+	Short: "Generate an install script from a saved scan",
+	Long: `Read a scan saved with "winclone scan -o programs.json" and write a
+PowerShell script that installs the same programs on a fresh machine.
+
+Each program is looked up in the package managers in order of preference:
+winget first, then Chocolatey. The first manager with a matching package
+wins. Programs no manager knows are written as TODO comments so nothing is
+silently dropped. Managers that aren't installed on this machine are skipped.
+
+- --manager: Comma-separated preference order (default: winget,choco)
+- --dry-run: Prints the script to stdout instead of writing it, and names the
+  file it would have written, so it can be reviewed first
+
+Examples:
+  winclone restore programs.json                      # Writes restore.ps1
+  winclone restore programs.json -o setup.ps1         # Choose the script name
+  winclone restore programs.json --manager choco      # Chocolatey only
+  winclone restore programs.json --manager choco,winget
+  winclone restore programs.json --dry-run            # Review the script first`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone restore <scan-file>"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		outputFile, _ := cmd.Flags().GetString("output")
+		managerList, _ := cmd.Flags().GetString("manager")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		managers, err := parseManagers(managerList)
+		if err != nil {
+			return err
+		}
+
+		result, err := loadScanFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", args[0], err)
+		}
+		programs := result.Programs
+		if !includeUpdates {
+			programs, _ = hideWindowsUpdates(programs) // Patches come with Windows Update, not a package manager
+		}
+		programs, _ = dedupPrograms(programs)
+
+		// Only managers that are installed here can be searched
+		var available []packageManager
+		for _, manager := range managers {
+			_, err := exec.LookPath(manager.Command)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s was not found, skipping it\n", manager.Label)
+				continue
+			}
+			available = append(available, manager)
+		}
+		if len(available) == 0 {
+			return fmt.Errorf("none of the package managers (%s) are installed", managerList)
+		}
+
+		fmt.Fprintf(os.Stderr, "Looking up %d programs...\n", len(programs))
+		matches := findPackages(programs, available)
+
+		// A dry run prints the script to stdout instead, and says where it would have gone
+		if dryRun {
+			err = saveRestoreScript(programs, matches, stdoutName)
+			if err != nil {
+				return fmt.Errorf("failed to write script: %v", err)
+			}
+			fmt.Fprintf(os.Stderr, "\nMatched %d of %d programs to packages\n", len(matches), len(programs))
+			fmt.Fprintf(os.Stderr, "Dry run: nothing was written. Without --dry-run this would be saved to: %s\n", outputFile)
+			return nil
+		}
+
+		err = saveRestoreScript(programs, matches, outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to save script: %v", err)
+		}
+
+		fmt.Printf("\nMatched %d of %d programs to packages\n", len(matches), len(programs))
+		fmt.Printf("Script saved to: %s\n", outputFile)
+
+		return nil
+	},
+}
+
+// packageManager is a package manager restore can generate commands for
+type packageManager struct {
+	Name    string                 // Name used with --manager
+	Label   string                 // Name shown to the user
+	Command string                 // Executable that must be on PATH
+	Search  func(string) string    // Looks up a package ID by program name ("" if none)
+	Install func(id string) string // The install command for a package ID
+}
+
+// packageManagers are the supported managers, by --manager name
+var packageManagers = map[string]packageManager{
+	"winget": {
+		Name:    "winget",
+		Label:   "winget",
+		Command: "winget",
+		Search:  searchWinget,
+		Install: func(id string) string {
+			return "winget install --id " + id + " -e --accept-package-agreements --accept-source-agreements"
+		},
+	},
+	"choco": {
+		Name:    "choco",
+		Label:   "Chocolatey",
+		Command: "choco",
+		Search:  searchChocolatey,
+		Install: func(id string) string {
+			return "choco install " + id + " -y"
+		},
+	},
+}
+
+// parseManagers turns a --manager value like "winget,choco" into managers in that order
+// "chocolatey" is accepted as another name for "choco"
+func parseManagers(list string) ([]packageManager, error) {
+	var managers []packageManager
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "chocolatey" {
+			name = "choco"
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+
+		manager, ok := packageManagers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown package manager %q (valid values: winget, choco)", name)
+		}
+		seen[name] = true
+		managers = append(managers, manager)
+	}
+
+	if len(managers) == 0 {
+		return nil, fmt.Errorf("--manager needs at least one package manager (winget, choco)")
+	}
+	return managers, nil
+}
+
+// packageMatch is the package a program will be installed from
+type packageMatch struct {
+	Manager packageManager
+	ID      string
+}
+
+// findPackages looks up each program in the managers, in order of preference
+// The result maps program names to their package; unmatched programs are left out
+func findPackages(programs []Program, managers []packageManager) map[string]packageMatch {
+	matches := make(map[string]packageMatch)
+	searched := make(map[string]packageMatch) // Cleaned name -> match, so we search each name once
+
+	tracker := newProgressTracker(len(programs))
+	for i, program := range programs {
+		if i%10 == 0 && i > 0 {
+			tracker.update(i)
+			fmt.Fprintf(os.Stderr, "  Looked up %d/%d programs...%s\n", i, len(programs), tracker.status())
+		}
+
+		name := cleanProgramName(program.Name)
+		match, done := searched[name]
+		if !done && name != "" {
+			for _, manager := range managers {
+				id := manager.Search(name)
+				if id != "" {
+					match = packageMatch{Manager: manager, ID: id}
+					break
+				}
+			}
+			searched[name] = match
+		}
+		if match.ID != "" {
+			matches[program.Name] = match
+		}
+	}
+
+	return matches
+}
+
+// searchChocolatey asks Chocolatey for a package with the given name
+// It returns the package ID, or "" when there is no clear match
+func searchChocolatey(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	output, err := exec.Command("choco", "search", name, "--limit-output").Output()
+	if err != nil {
+		return ""
+	}
+
+	ids := parseChocolateyList(string(output))
+
+	// Chocolatey IDs are usually the name in lowercase without spaces, e.g.
+	// "Google Chrome" -> "googlechrome", so compare with spaces and dashes removed
+	want := strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(name))
+	for _, id := range ids {
+		if strings.ReplaceAll(strings.ToLower(id), "-", "") == want {
+			return id
+		}
+	}
+
+	// Otherwise only trust the result if it's the only one
+	if len(ids) == 1 {
+		return ids[0]
+	}
+	return ""
+}
+
+// parseChocolateyList reads the package IDs from "choco search --limit-output"
+// Each line is "id|version"
+func parseChocolateyList(output string) []string {
+	var ids []string
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		id, _, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// saveRestoreScript writes a PowerShell script of install commands
+// Programs without a package are written as TODO comments so nothing is silently dropped
+func saveRestoreScript(programs []Program, matches map[string]packageMatch, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	// Write header
+	fmt.Fprintf(file, "# WinClone - restore script\n")
+	fmt.Fprintf(file, "# Matched %d of %d programs\n\n", len(matches), len(programs))
+
+	// Write the install commands first, then the programs we couldn't match
+	// Several registry entries can map to one package, so each command is written once
+	written := make(map[string]bool)
+	for _, program := range programs {
+		match, ok := matches[program.Name]
+		if !ok {
+			continue
+		}
+		command := match.Manager.Install(match.ID)
+		if written[command] {
+			continue
+		}
+		written[command] = true
+		fmt.Fprintf(file, "%s\n", command)
+	}
+
+	fmt.Fprintf(file, "\n# Programs without a package (install these manually):\n")
+	for _, program := range programs {
+		if _, ok := matches[program.Name]; !ok {
+			fmt.Fprintf(file, "# TODO: %s\n", programLabel(program))
+		}
+	}
+
+	return file.Commit()
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringP("output", "o", "restore.ps1", "PowerShell script to write")
+
+	// Add the --manager flag to choose which package managers to use, and in what order
+	restoreCmd.Flags().String("manager", "winget,choco", "Package managers to try, in order of preference (winget, choco)")
+
+	// Add the --dry-run flag to review the script before it's written
+	restoreCmd.Flags().Bool("dry-run", false, "Print the script to stdout instead of writing it")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(restoreCmd)
+}
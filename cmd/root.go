@@ -6,10 +6,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// winCloneVersion is the released version of this tool
+// It is also written into wrapped JSON output so consumers know who produced it
+const winCloneVersion = "1.1.0"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "winclone",
-	Short: "Windows Program Scanner - Scan and list installed programs",
+	Use:     "winclone",
+	Version: winCloneVersion,
+	Short:   "Windows Program Scanner - Scan and list installed programs",
 	Long: `WinClone is a simple tool that scans the Windows registry to find 
 all installed programs and displays them in a clean, organized list.
 
@@ -17,16 +22,33 @@ It shows:
 - Program name
 - Version number (if available)  
 - Installation path (if available)
+- Publisher (if available)
+- Install date (if available)
+- Estimated size on disk (if available), e.g. "312.5 MB"
 
 The tool scans both 64-bit and 32-bit programs from the Windows registry.`,
 	Example: `winclone scan                    # Display programs on screen
 winclone scan -o programs.json   # Save as JSON file
 winclone scan -o programs.txt     # Save as text file
 winclone help                     # Show this help`,
+
+	// Commands return their errors, which cobra prints as "Error: ..." on stderr
+	// A failed scan isn't a usage mistake, so don't print the usage after it
+	SilenceUsage: true,
+
+	// Fill in defaults from winclone.toml before any command runs
+	// The config commands skip it, so a broken file can still be replaced
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if cmd == configCmd || cmd.Parent() == configCmd {
+			return nil
+		}
+		return applyConfig(cmd)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Any error returned by a command ends the process with exit code 1
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
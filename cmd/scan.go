@@ -1,13 +1,32 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
+	"gopkg.in/yaml.v3"
 )
 
 // scanCmd represents the scan command
@@ -18,229 +37,1462 @@ var scanCmd = &cobra.Command{
 
 This command will:
 1. Open the Windows registry
-2. Look in the Uninstall keys for both 64-bit and 32-bit programs
-3. Extract program names, versions, and installation paths
-4. Display the results in a clean format
+2. Look in the Uninstall keys for 64-bit, 32-bit and per-user programs
+3. Extract program names, versions, installation paths, publishers, and install dates
+4. Merge duplicates registered in both views (same name, version and
+   publisher), keeping the 64-bit entry; use --no-dedup to keep both
+5. Display the results in a clean format
 
 The registry locations scanned:
 - SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall (64-bit programs)
-- SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall (32-bit programs)
+- The same key in the 32-bit registry view, i.e. SOFTWARE\WOW6432Node\... (32-bit programs)
+- HKEY_CURRENT_USER\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall (per-user programs)
+
+The 64-bit and 32-bit views are opened with the KEY_WOW64_64KEY and
+KEY_WOW64_32KEY access flags rather than by the WOW6432Node path, which is how
+Windows expects them to be read.
+
+Filtering:
+- --filter / -f: Only includes programs whose name (or publisher) contains the
+  given text (case-insensitive), e.g. -f python
+- --filter-regex: Only includes programs whose name matches a Go regular
+  expression, e.g. --filter-regex "^(?i)microsoft visual c\+\+ 20(15|17|19)".
+  Add (?i) for case-insensitive matching. Can't be combined with --filter.
+- --publisher / -p: Only includes programs whose publisher contains the given
+  text (case-insensitive), for both screen display and file export
+- --arch x64|x86 (or --filter-arch): Only includes 64-bit or 32-bit programs,
+  handy for finding legacy 32-bit software that's due for an upgrade
+- --scope machine|user|all: Only includes programs installed for everyone
+  (HKLM), only those installed just for the current user (HKCU), or both (default)
+- --registry-view both|32|64: Scans both registry views (default), or only the
+  32-bit or 64-bit one. Per-user programs are always scanned, since HKCU isn't split
+- --exclude TEXT: Hides programs whose name contains the text (case-insensitive).
+  Repeat it to hide several kinds, e.g. --exclude "Visual C++" --exclude Redistributable.
+  Prefix a pattern with re: to use a regular expression, e.g. --exclude "re:^KB\d+"
+- --min-size SIZE: Only includes programs of at least SIZE, e.g. --min-size 500MB
+  or --min-size 2GB (KB, MB and GB are accepted). Programs that don't record a
+  size are left out, and the summary says how many. Pairs well with --sort size
+- --modified-since DATE: Only includes programs installed on or after DATE,
+  given as 2024-01-14 or as days back like 7d or 30d. Programs without an
+  install date are left out, and the summary says how many. Handy for incident
+  response: what was installed this week?
+- --include-store: Also lists Microsoft Store (AppX) packages for the current
+  user. These come from PowerShell's Get-AppxPackage rather than the registry;
+  if PowerShell isn't available a warning is shown and the scan carries on
+- --include-system (or --include-system-components): Also lists entries marked
+  SystemComponent=1, which Windows hides from "Add or Remove Programs" (hidden
+  by default; the summary says how many)
+- --include-updates: Also lists patch entries such as "Security Update for ...",
+  "Hotfix for ..." or names with a KB number, hidden by default as they aren't
+  software to inventory (the summary says how many)
+
+Sorting:
+- --sort name: Alphabetical by name (default)
+- --sort version: Oldest version first (1.9 sorts before 1.10)
+- --sort date: Most recently installed first
+- --sort size: Largest first, to find space hogs
+- --sort publisher: Alphabetical by publisher, then by name
+- --limit N: Keeps only the first N programs after sorting, for screen and file
+  output alike, e.g. --sort size --limit 10 for the ten biggest programs
 
 Output Options:
 - Display on screen (default): Shows programs in a numbered list
 - JSON file (.json): Saves structured data for programming/APIs
+- NDJSON file (.ndjson/.jsonl): Saves one program object per line (JSON Lines),
+  for jq and log aggregators that read line by line
 - Text file (.txt): Saves human-readable format for documentation
+- HTML file (.html): Saves a report with a summary, search box and sortable table
+- Markdown file (.md): Saves a titled, timestamped table for wikis and documentation
+- YAML file (.yaml/.yml): Saves a list with snake_case keys for Ansible/Salt
+- XML file (.xml): Saves a <Programs> document that PowerShell's [xml] can read
+- SQLite database (.db/.sqlite): Adds the programs to a "programs" table; running
+  again updates existing rows, so one database can collect many scans and machines
+- CSV file (.csv): Saves a spreadsheet-friendly table (Name, Version, Path, Publisher,
+  Architecture, MSIProductCode, BundleUpgradeCode, ModifyPath, RepairString)
+- --output-format FORMAT: Picks the -o format instead of the file extension
+  (json, ndjson, text, csv, yaml, xml, markdown, html or sqlite). Without -o, the output is
+  written to stdout, e.g. --output-format csv > programs.dat. "-o -" also
+  writes to stdout. An -o extension not listed above is an error rather than
+  a text file with a misleading name; add --output-format to save it anyway,
+  e.g. -o programs.dat --output-format json
+- Files are first written to a temporary file in the same folder and renamed
+  over the target once complete, so a crash or Ctrl+C never leaves a truncated
+  file (the previous one stays as it was). SQLite databases use a transaction
+- --format json: Prints JSON to the screen instead of the numbered list
+- --format markdown: Prints a Markdown table to paste into a wiki page
+- --format table: Prints Name, Version and Publisher in aligned columns with a
+  header row. Long values end in "..." so rows fit in --width characters
+  (default 80; 0 never cuts). The default numbered list stays available as text
+- --format json,text: Prints both, one after the other, with a delimiter line
+- JSON is written as {"schemaVersion", "winCloneVersion", ..., "programs": [...]}
+  so consumers know which layout they're reading and where it came from.
+  The envelope records the timestamp, hostname, Windows version, the user who
+  ran the scan, the number of programs and an optional --label such as
+  "pre-migration baseline". Read the list from .programs
+- --interactive: After scanning, shows a checkbox list to pick the programs to
+  show or save with -o, e.g. for a custom clone script. Type to filter, Tab
+  toggles (so does Space while the filter is empty; otherwise it's typed into
+  the filter), Ctrl+A selects all, Enter saves and Esc cancels. Falls back to
+  the full list when the output isn't a console
+- --wrap=false: Writes JSON as a bare array instead, like earlier versions did
+- --output-append: Adds this scan to the -o file instead of replacing it, to
+  collect scans over time. A JSON file becomes an array of wrapped scans (an
+  existing single scan is kept as the first element); CSV and text files get
+  the new rows after a separator line, and NDJSON files just get more lines.
+  diff, compare and restore read the last (most recent) scan of such a JSON file
+- --stream: Prints each program to stdout as a line of NDJSON the moment it's
+  read, instead of waiting for the whole scan, e.g. for a pipeline that starts
+  work right away. The output is not deduplicated: a program registered in both
+  the 64-bit and 32-bit views is printed twice. Flags that need the full list
+  (filters, sorting, --limit, --format, the reports, --strict and the JSON
+  envelope options) are refused with it. Entries that couldn't be read are
+  counted on stderr. System components and Windows updates are still left out
+  unless --include-system or --include-updates is given
+- --template TEXT: Writes the results with your own Go text/template instead of
+  a built-in format, to the screen or to -o. The template sees .Programs and
+  .Meta (the JSON envelope: .Meta.Hostname, .Meta.Timestamp, ...), plus the
+  helpers size, lower, upper and join. --template-file reads it from a file
+- --group-by source: Groups the screen list by registry location (HKLM 64-bit,
+  WOW6432Node, ...) with a count per group. File output stays a flat list
+- --group-by publisher: Groups the screen list under publisher headings such as
+  "Microsoft Corporation (12 programs)", alphabetically, with programs sorted by
+  name in each group and an "Unknown Publisher" group at the end
+- --age: Shows a relative age like "installed 3 months ago" on screen, using
+  InstallDate or, if that's missing, the registry key's last-write time
+- --raw-sizes: Shows sizes as kilobyte integers instead of "1.2 GB" (JSON always uses SizeKB)
+- --verbose / -v: Shows the scan's step-by-step progress, and each program's
+  homepage, support link, uninstall/modify/repair commands and MSI codes on
+  screen (file output such as JSON always includes URLInfoAbout, HelpLink,
+  UninstallString, QuietUninstallString, ModifyPath, RepairString,
+  MSIProductCode and BundleUpgradeCode). Progress is written to stderr,
+  so "winclone scan -v > programs.txt" still gives a clean file
+- --quiet / -q: Prints nothing on success, not even "Results saved" or
+  progress; errors and warnings go to stderr. Meant for scripts and scheduled
+  tasks, e.g. -o scan.json -q
+
+Performance:
+- --parallel N (or --workers N): Reads N registry entries at the same time
+  (default: the number of logical CPUs). Use --parallel 1 for a sequential scan
+
+Strict Mode:
+- --strict: Fails (exit code 1) with a report of every entry that couldn't be
+  read, instead of silently skipping it. Entries without a DisplayName are
+  normal metadata and are still skipped unless --strict-unnamed is also given.
+- Any error (a failed scan, an unwritable output file, a bad flag value) also
+  ends WinClone with exit code 1, so scripts can rely on the exit status
+
+Watch Mode:
+- --watch 30s: Rescans every 30 seconds (any duration like 10s or 5m), clearing
+  the screen each time to show the current count and the programs that
+  appeared or disappeared since the previous scan. Press Ctrl+C to stop.
+  The filter and output flags don't apply in watch mode
+
+Caching:
+- A scan is cached in %TEMP%\winclone_cache.json. Running scan again within
+  --cache-ttl (default 5m) reuses it instead of reading the registry, unless a
+  program was installed or removed since (the Uninstall keys' last-write time
+  is checked). --cache-ttl 0 or --no-cache always scans. --strict,
+  --show-errors, --cross-check, --changed-since-cache, --timing and
+  --include-store always scan too
+
+Change Tracking:
+- --changed-since-cache: Compares the scan with the one cached by the previous
+  run (%TEMP%\winclone_cache.json), prints only added, removed and changed
+  programs, then updates the cache. The first run just saves a baseline.
+
+Diagnostics:
+- --cross-check: Counts the entries Control Panel would show (those with a
+  DisplayName and without SystemComponent=1, including per-user HKCU entries)
+  and warns if the scan found noticeably fewer, pointing at the likely cause
+- --show-errors: Prints (to stderr) how many registry entries were skipped and
+  why: access denied, no DisplayName, or another error, with each failing key.
+  Access-denied entries usually mean WinClone should be run as Administrator
+- --timing: Prints (to stderr) how long the scan spent opening the Uninstall
+  keys, reading their subkey names and reading each program's values, plus the
+  total, to see where a slow scan spends its time
+
+Audit Reports:
+- --path-conflicts: Lists install locations shared by several programs or
+  nested inside another program's directory (programs without a path are skipped)
+- --arch-mismatch: Lists 64-bit registry entries installed into "Program Files (x86)"
+  and 32-bit entries installed into "Program Files"
 
 Examples:
   winclone scan                    # Display on screen
   winclone scan -o programs.json   # Save as JSON
-  winclone scan -o programs.txt    # Save as text file`,
-	Run: func(cmd *cobra.Command, args []string) {
+  winclone scan -o programs.txt    # Save as text file
+  winclone scan -o programs.csv    # Save as CSV for Excel
+  winclone scan --format json,text # Print JSON, then the human list
+  winclone scan -p microsoft       # Only Microsoft software
+  winclone scan -f python          # Is Python installed?
+  winclone scan --arch x86         # Legacy 32-bit software
+  winclone scan --min-size 1GB --sort size  # Programs of 1 GB or more, largest first
+  winclone scan --modified-since 7d --sort date  # What was installed this week?`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// This function runs when the user types "winclone scan"
-		fmt.Println("WinClone - Scanning installed programs...")
-		fmt.Println("==========================================")
+		// Step-by-step progress is only shown with --verbose, and goes to stderr
+		// so "winclone scan -v > out.txt" still produces a clean file
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		var progress io.Writer = io.Discard
+		if verbose {
+			progress = os.Stderr
+		}
+		fmt.Fprintln(progress, "WinClone - Scanning installed programs...")
+		fmt.Fprintln(progress, "==========================================")
 
-		// Run the scan directly - no need for a scanner struct!
-		programs, err := scanAllPrograms()
+		// Read the output options first so bad values fail before the scan
+		opts, err := outputOptionsFromFlags(cmd)
 		if err != nil {
-			fmt.Printf("Error scanning programs: %v\n", err)
-			return
+			return err
 		}
-
-		// Check if user wants file output
 		outputFile, _ := cmd.Flags().GetString("output")
-		if outputFile != "" {
-			// Determine format based on file extension
-			if strings.HasSuffix(strings.ToLower(outputFile), ".json") {
-				// Save to JSON file
-				err := saveToJSON(programs, outputFile)
+		if outputFile != "" && opts.Template == nil {
+			format, err := outputFormatFor(outputFile, opts.OutputFormat)
+			if err != nil {
+				return err
+			}
+			if opts.Append && !appendFormats[format] {
+				return fmt.Errorf("--output-append only works with JSON, NDJSON, CSV and text files, not %s", outputFormats[format])
+			}
+		}
+		if opts.Append && (outputFile == "" || outputFile == stdoutName || opts.Template != nil) {
+			return fmt.Errorf("--output-append needs -o with a JSON, NDJSON, CSV or text file")
+		}
+		if opts.Quiet && verbose {
+			return fmt.Errorf("--quiet and --verbose can't be used together")
+		}
+
+		// Status messages like "No programs matched" are dropped with --quiet
+		var status io.Writer = os.Stdout
+		if opts.Quiet {
+			status = io.Discard
+		}
+
+		sortKey, _ := cmd.Flags().GetString("sort")
+		sortKey = strings.ToLower(sortKey)
+		err = validateSortKey(sortKey)
+		if err != nil {
+			return err
+		}
+		scope, _ := cmd.Flags().GetString("scope")
+		scope = strings.ToLower(scope)
+		err = validateScope(scope)
+		if err != nil {
+			return err
+		}
+		modifiedSince, _ := cmd.Flags().GetString("modified-since")
+		var since time.Time
+		if modifiedSince != "" {
+			since, err = parseSince(modifiedSince, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		minSize, _ := cmd.Flags().GetString("min-size")
+		var minSizeKB uint64
+		if minSize != "" {
+			minSizeKB, err = parseSize(minSize)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Run the scan
+		workersFlag := "workers"
+		if cmd.Flags().Changed("parallel") {
+			workersFlag = "parallel"
+		}
+		workers, _ := cmd.Flags().GetInt(workersFlag)
+		if workers < 1 {
+			return fmt.Errorf("--%s must be at least 1, got %d", workersFlag, workers)
+		}
+		scanner := newScanner(workers)
+		scanner.IncludeStore, _ = cmd.Flags().GetBool("include-store")
+		scanner.Progress = progress
+		registryView, _ := cmd.Flags().GetString("registry-view")
+		registryView = strings.ToLower(registryView)
+		scanner.Locations, err = locationsForView(scanner.Locations, registryView)
+		if err != nil {
+			return err
+		}
+
+		// In watch mode, keep rescanning until Ctrl+C instead of printing one list
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		watch, _ := cmd.Flags().GetString("watch")
+		if watch != "" {
+			interval, err := time.ParseDuration(watch)
+			if err != nil || interval <= 0 {
+				return fmt.Errorf("invalid --watch interval %q (use a duration like 30s or 5m)", watch)
+			}
+			return watchScan(scanner, interval, includeUpdates)
+		}
+
+		// With --stream, print each program as NDJSON as soon as it's read instead
+		includeSystem, _ := cmd.Flags().GetBool("include-system")
+		if cmd.Flags().Changed("include-system-components") {
+			includeSystem, _ = cmd.Flags().GetBool("include-system-components")
+		}
+		stream, _ := cmd.Flags().GetBool("stream")
+		if stream {
+			return streamScan(scanner, includeSystem, includeUpdates)
+		}
+
+		// Reuse a recent scan from the cache when nothing has been installed since
+		// Strict mode and the error reports need the skipped entries,
+		// --changed-since-cache needs a fresh scan to compare, and Store packages
+		// aren't cached, so those always scan. The cache holds both registry views,
+		// so --registry-view 32 or 64 scans too
+		strict, _ := cmd.Flags().GetBool("strict")
+		strictUnnamed, _ := cmd.Flags().GetBool("strict-unnamed")
+		changedSinceCache, _ := cmd.Flags().GetBool("changed-since-cache")
+		showErrors, _ := cmd.Flags().GetBool("show-errors")
+		crossCheck, _ := cmd.Flags().GetBool("cross-check")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+		timing, _ := cmd.Flags().GetBool("timing")
+		useCache := !noCache && !strict && !showErrors && !crossCheck && !changedSinceCache && !timing && !scanner.IncludeStore &&
+			registryView == registryViewBoth
+
+		var programs []Program
+		var skipped []skippedEntry
+		cached := false
+		if useCache {
+			programs, cached = loadCachedScan(scanner, cacheTTL)
+			if cached {
+				fmt.Fprintf(progress, "Using the cached scan in %s (use --no-cache to scan again)\n", cacheFilePath())
+			}
+		}
+		if !cached {
+			programs, skipped, err = scanner.scanAllPrograms()
+			if err != nil {
+				return fmt.Errorf("failed to scan programs: %v", err)
+			}
+			if timing {
+				displayTiming(os.Stderr, scanner.Timing, scanner.Workers)
+			}
+			if useCache {
+				err = saveScanCache(programs)
 				if err != nil {
-					fmt.Printf("Error saving to JSON: %v\n", err)
-					return
+					fmt.Fprintf(os.Stderr, "Warning: Could not update the scan cache: %v\n", err)
 				}
-				fmt.Printf("\nResults saved to JSON: %s\n", outputFile)
-			} else {
-				// Save to text file
-				err := saveToText(programs, outputFile)
-				if err != nil {
-					fmt.Printf("Error saving to text: %v\n", err)
-					return
+			}
+		}
+
+		// In strict mode any skipped entry means the scan is incomplete, so fail
+		// instead of writing a partial inventory
+		if strict {
+			failures := strictFailures(skipped, strictUnnamed)
+			if len(failures) > 0 {
+				displaySkipped(failures)
+				return fmt.Errorf("scan incomplete: %d registry entries could not be read (--strict)", len(failures))
+			}
+		}
+
+		// Report changes since the previous run instead of the full list
+		if changedSinceCache {
+			err := reportChangesSinceCache(programs)
+			if err != nil {
+				return fmt.Errorf("failed to compare with cache: %v", err)
+			}
+			return nil
+		}
+
+		// Hide system components like Windows does, unless asked not to
+		if !includeSystem {
+			programs, opts.SystemHidden = hideSystemComponents(programs)
+		}
+
+		// Keep the full scan for the Control Panel cross-check, which compares
+		// against the registry; Control Panel lists update entries, so they stay in
+		allPrograms := programs
+
+		// Hide Windows and Office patches, which aren't software to inventory
+		if !includeUpdates {
+			programs, opts.UpdatesHidden = hideWindowsUpdates(programs)
+		}
+
+		// Collapse programs registered in both the 64-bit and 32-bit views
+		noDedup, _ := cmd.Flags().GetBool("no-dedup")
+		if !noDedup {
+			programs, opts.DuplicatesRemoved = dedupPrograms(programs)
+		}
+
+		// Narrow the list down by name (or publisher) if requested
+		filter, _ := cmd.Flags().GetString("filter")
+		if filter != "" {
+			programs = filterByText(programs, filter)
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo programs matched filter %q\n", filter)
+				return nil
+			}
+		}
+
+		// Narrow the list down by a regular expression on the name if requested
+		filterRegex, _ := cmd.Flags().GetString("filter-regex")
+		if filterRegex != "" {
+			programs, err = filterByRegex(programs, filterRegex)
+			if err != nil {
+				return err
+			}
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo programs matched pattern %q\n", filterRegex)
+				return nil
+			}
+		}
+
+		// Narrow the list down to one vendor if requested
+		publisher, _ := cmd.Flags().GetString("publisher")
+		if publisher != "" {
+			programs = filterByPublisher(programs, publisher)
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo programs matched publisher %q\n", publisher)
+				return nil
+			}
+		}
+
+		// Show only 32-bit or only 64-bit programs if requested
+		filterArch, _ := cmd.Flags().GetString("filter-arch")
+		if cmd.Flags().Changed("arch") {
+			filterArch, _ = cmd.Flags().GetString("arch")
+		}
+		if filterArch != "" {
+			programs, err = filterByArchitecture(programs, filterArch)
+			if err != nil {
+				return err
+			}
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo %s programs found\n", filterArch)
+				return nil
+			}
+		}
+
+		// Show only machine-wide or only per-user programs if requested
+		if scope != scopeAll {
+			programs = filterByScope(programs, scope)
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo %s programs found\n", scope)
+				return nil
+			}
+		}
+
+		// Drop programs matching any --exclude pattern
+		excludes, _ := cmd.Flags().GetStringArray("exclude")
+		if len(excludes) > 0 {
+			programs, opts.Excluded, err = excludeByName(programs, excludes)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Show only programs of at least --min-size
+		if minSize != "" {
+			programs, opts.NoSize = filterByMinSize(programs, minSizeKB)
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo programs of %s or more found\n", minSize)
+				if opts.NoSize > 0 {
+					fmt.Fprintf(status, "(%d programs without a size were left out)\n", opts.NoSize)
+				}
+				return nil
+			}
+		}
+
+		// Show only programs installed on or after --modified-since
+		if modifiedSince != "" {
+			programs, opts.NoDate = filterByInstalledSince(programs, since)
+			if len(programs) == 0 {
+				fmt.Fprintf(status, "\nNo programs installed since %s found\n", since.Format("2006-01-02"))
+				if opts.NoDate > 0 {
+					fmt.Fprintf(status, "(%d programs without an install date were left out)\n", opts.NoDate)
 				}
-				fmt.Printf("\nResults saved to text: %s\n", outputFile)
+				return nil
+			}
+		}
+
+		// Put the list in the requested order
+		err = sortPrograms(programs, sortKey)
+		if err != nil {
+			return err
+		}
+
+		// Cut the list down to the first N programs if requested
+		// The audit reports below still look at every program
+		listed := programs
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit > 0 && limit < len(programs) {
+			listed = programs[:limit]
+			opts.TotalFound = len(programs)
+		}
+
+		// Let the user tick the programs to keep, if requested
+		// Without a console (e.g. output piped to a file) the whole list is used
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		if interactive && !(isConsole(os.Stdin) && isConsole(os.Stdout)) {
+			fmt.Fprintln(os.Stderr, "Warning: --interactive needs a console; using the full list")
+		} else if interactive {
+			chosen, ok, err := selectPrograms(listed)
+			if err != nil {
+				return err
 			}
-		} else {
-			// Display the results on screen
-			displayResults(programs)
+			if !ok {
+				fmt.Fprintln(os.Stderr, "Selection cancelled, nothing was saved")
+				return nil
+			}
+			if len(chosen) < len(programs) {
+				opts.TotalFound = len(programs)
+			}
+			listed = chosen
+		}
+
+		// Check if user wants file output
+		// --output-format without -o writes that format to stdout
+		if outputFile == "" && opts.OutputFormat != "" {
+			outputFile = stdoutName
+		}
+		pathConflicts, _ := cmd.Flags().GetBool("path-conflicts")
+		archMismatch, _ := cmd.Flags().GetBool("arch-mismatch")
+		reportOnly := pathConflicts || archMismatch // Audit reports replace the normal list
+		format, _ := cmd.Flags().GetString("format")
+		if opts.Template != nil {
+			// A custom template replaces the built-in formats, on screen or in -o
+			err := saveWithTemplate(listed, outputFile, opts)
+			if err != nil {
+				return err
+			}
+		} else if outputFile != "" {
+			// Save to a file, picking the format from the extension
+			err := saveResults(listed, outputFile, opts)
+			if err != nil {
+				return err
+			}
+		} else if !reportOnly && !opts.Quiet {
+			// Display the results on screen in the requested format(s)
+			err := printFormats(listed, format, opts)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Show the install location audit if requested
+		if pathConflicts {
+			displayPathConflicts(programs)
+		}
+
+		// Show the architecture audit if requested
+		if archMismatch {
+			displayArchMismatches(programs)
+		}
+
+		// Compare against what Control Panel would show, if requested
+		if crossCheck {
+			displayCrossCheck(allPrograms, skipped)
+		}
+
+		// Summarize the entries that couldn't be read, if requested
+		// This goes to stderr so it never mixes with JSON or CSV on stdout
+		if showErrors {
+			displayScanErrors(os.Stderr, skipped)
 		}
+
+		return nil
 	},
 }
 
+// schemaVersion identifies the layout of the wrapped JSON output
+// Bump it whenever Program fields are added, removed or change meaning,
+// and add a line to the list below
+//
+// Versions:
+//   - 1: Name, Version, Path and SizeKB; envelope with schemaVersion,
+//     winCloneVersion, timestamp, hostname and programs
+//   - 2: Adds Publisher, Architecture, ArchMismatch, Source, Scope, InstallDate,
+//     InstallDateRaw, LastWriteTime, SystemComponent, WindowsInstaller,
+//     ParentKeyName, UninstallString, QuietUninstallString, ModifyPath,
+//     RepairString, URLInfoAbout, HelpLink, MSIProductCode, BundleUpgradeCode
+//     and Icon; the envelope adds osVersion, user, label and totalCount;
+//     SizeKB is always written, as 0 when the size is unknown
+const schemaVersion = 2
+
 // Program represents an installed application
 type Program struct {
-	Name    string // Display name of the program
-	Version string // Version number
-	Path    string // Installation path
+	Name      string `yaml:"name"`                               // Display name of the program
+	Version   string `yaml:"version,omitempty"`                  // Version number
+	Path      string `yaml:"path,omitempty"`                     // Installation path
+	Publisher string `yaml:"publisher,omitempty"`                // Company that made the software
+	SizeKB    uint64 `xml:",omitempty" yaml:"size_kb,omitempty"` // Estimated size on disk in kilobytes (0 if unknown, but always in JSON)
+
+	Architecture string `json:",omitempty" xml:",omitempty" yaml:"architecture,omitempty"`  // "x64" or "x86", based on the registry location it came from
+	ArchMismatch bool   `json:",omitempty" xml:",omitempty" yaml:"arch_mismatch,omitempty"` // True when Path points at the other architecture's Program Files
+
+	Source string `json:",omitempty" xml:",omitempty" yaml:"source,omitempty"` // Registry location the entry was read from, e.g. "HKLM 64-bit"
+	Scope  string `json:",omitempty" xml:",omitempty" yaml:"scope,omitempty"`  // "machine" for everyone, "user" for the current user only
+
+	InstallDate    *time.Time `json:",omitempty" xml:",omitempty" yaml:"install_date,omitempty"`     // Parsed from the InstallDate value (nil if missing or malformed)
+	InstallDateRaw string     `json:",omitempty" xml:",omitempty" yaml:"install_date_raw,omitempty"` // The InstallDate value as stored, when it couldn't be parsed
+	LastWriteTime  *time.Time `json:",omitempty" xml:",omitempty" yaml:"last_write_time,omitempty"`  // When the program's registry key was last modified
+
+	SystemComponent  bool   `json:",omitempty" xml:",omitempty" yaml:"system_component,omitempty"`  // True when SystemComponent=1 (hidden by Windows)
+	WindowsInstaller bool   `json:",omitempty" xml:",omitempty" yaml:"windows_installer,omitempty"` // True when WindowsInstaller=1 (installed from an MSI package)
+	ParentKeyName    string `json:",omitempty" xml:",omitempty" yaml:"parent_key_name,omitempty"`   // Subkey of the program this entry belongs to, if any
+
+	UninstallString      string `json:",omitempty" xml:",omitempty" yaml:"uninstall_string,omitempty"`       // Command that uninstalls the program
+	QuietUninstallString string `json:",omitempty" xml:",omitempty" yaml:"quiet_uninstall_string,omitempty"` // Command that uninstalls it without prompts, if provided
+	ModifyPath           string `json:",omitempty" xml:",omitempty" yaml:"modify_path,omitempty"`            // Command that changes the installed features, if provided
+	RepairString         string `json:",omitempty" xml:",omitempty" yaml:"repair_string,omitempty"`          // Command that repairs the install (built from the ProductCode for MSI packages)
+
+	URLInfoAbout string `json:",omitempty" xml:",omitempty" yaml:"url_info_about,omitempty"` // The program's homepage
+	HelpLink     string `json:",omitempty" xml:",omitempty" yaml:"help_link,omitempty"`      // Where to get support
+
+	MSIProductCode    string `json:",omitempty" xml:",omitempty" yaml:"msi_product_code,omitempty"`    // The subkey's GUID, for programs installed from an MSI package
+	BundleUpgradeCode string `json:",omitempty" xml:",omitempty" yaml:"bundle_upgrade_code,omitempty"` // UpgradeCode of a bundle installer (e.g. WiX Burn), if provided
+
+	Icon string `json:",omitempty" xml:"-" yaml:"-"` // Path of the program's icon file (JSON only, for GUIs built on the scan data)
+}
+
+// ScanResult is the wrapped JSON document written by default (unless --wrap=false)
+// It tells consumers which schema they are reading and where the data came from
+type ScanResult struct {
+	SchemaVersion   int       `json:"schemaVersion"`       // Layout version of this document
+	WinCloneVersion string    `json:"winCloneVersion"`     // Version of WinClone that wrote it
+	Timestamp       time.Time `json:"timestamp"`           // When the scan was taken
+	Hostname        string    `json:"hostname"`            // Machine the scan was taken on
+	OSVersion       string    `json:"osVersion,omitempty"` // Windows version, e.g. "10.0.22631"
+	User            string    `json:"user"`                // Account that ran the scan
+	Label           string    `json:"label,omitempty"`     // Optional note from --label
+	TotalCount      int       `json:"totalCount"`          // Number of programs, so readers needn't count
+	Programs        []Program `json:"programs"`            // The scanned programs
 }
 
-// scanAllPrograms scans both 64-bit and 32-bit program locations
-// This is the main function that coordinates the entire scanning process
-func scanAllPrograms() ([]Program, error) {
-	var allPrograms []Program
+// newScanResult wraps a program list with the current schema and machine details
+// label is an optional free-text note such as "pre-migration baseline"
+func newScanResult(programs []Program, label string) ScanResult {
+	hostname, _ := os.Hostname() // Leave it empty if the name can't be read
 
-	// Step 1: Scan 64-bit programs
-	fmt.Println("Step 1: Scanning 64-bit programs...")
-	fmt.Println("Location: SOFTWARE\\Microsoft\\Windows\\CurrentVersion\\Uninstall")
+	// Record who ran the scan, handy when reviewing a pile of archived reports
+	username := ""
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
 
-	programs64, err := scanRegistryLocation(`SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`)
-	if err != nil {
-		fmt.Printf("Warning: Could not scan 64-bit programs: %v\n", err)
-	} else {
-		fmt.Printf("Found %d 64-bit programs\n", len(programs64))
-		allPrograms = append(allPrograms, programs64...)
+	return ScanResult{
+		SchemaVersion:   schemaVersion,
+		WinCloneVersion: winCloneVersion,
+		Timestamp:       time.Now(),
+		Hostname:        hostname,
+		OSVersion:       osVersion(),
+		User:            username,
+		Label:           label,
+		TotalCount:      len(programs),
+		Programs:        programs,
 	}
+}
+
+// osVersion returns the Windows version as "major.minor.build", e.g. "10.0.22631"
+// RtlGetVersion reports the real version even when the manifest doesn't declare it
+func osVersion() string {
+	info := windows.RtlGetVersion()
+	return fmt.Sprintf("%d.%d.%d", info.MajorVersion, info.MinorVersion, info.BuildNumber)
+}
+
+// outputOptions holds the flags that change how results are written
+type outputOptions struct {
+	RawSizes bool   // Show sizes as plain kilobyte integers
+	Wrap     bool   // Wrap JSON in a ScanResult object
+	Label    string // Free-text label stored in wrapped JSON
+	Age      bool   // Show "installed 3 months ago" style ages on screen
+	GroupBy  string // Group the screen list by this field ("" for a flat list)
+	Verbose  bool   // Show extra details such as uninstall commands on screen
+	Quiet    bool   // Print nothing but errors (and reports that were asked for)
+
+	OutputFormat string             // Format for -o from --output-format ("" to use the file extension)
+	Template     *template.Template // Custom output from --template or --template-file (nil for none)
+	Width        int                // Line width --format table fits its rows into (0 for no limit)
+	Append       bool               // Add to the -o file instead of replacing it (--output-append)
+
+	DuplicatesRemoved int // Shown in the summary so a lower total makes sense
+	SystemHidden      int // System components left out, also shown in the summary
+	UpdatesHidden     int // Windows update entries left out, also shown in the summary
+	TotalFound        int // Programs found before --limit cut the list (0 when it didn't)
+	Excluded          int // Programs dropped by --exclude
+	NoSize            int // Programs without a size, dropped by --min-size
+	NoDate            int // Programs without an install date, dropped by --modified-since
+}
+
+// outputOptionsFromFlags reads the output-related flags from the command line
+func outputOptionsFromFlags(cmd *cobra.Command) (outputOptions, error) {
+	var opts outputOptions
+	opts.RawSizes, _ = cmd.Flags().GetBool("raw-sizes")
+	opts.Wrap, _ = cmd.Flags().GetBool("wrap")
+	opts.Label, _ = cmd.Flags().GetString("label")
+	opts.Age, _ = cmd.Flags().GetBool("age")
+	opts.GroupBy, _ = cmd.Flags().GetString("group-by")
+	opts.Verbose, _ = cmd.Flags().GetBool("verbose")
+	opts.Quiet, _ = cmd.Flags().GetBool("quiet")
+	opts.OutputFormat, _ = cmd.Flags().GetString("output-format")
+	opts.Width, _ = cmd.Flags().GetInt("width")
+	opts.Append, _ = cmd.Flags().GetBool("output-append")
 
-	// Step 2: Scan 32-bit programs (WOW64 = Windows on Windows 64-bit)
-	fmt.Println("\nStep 2: Scanning 32-bit programs...")
-	fmt.Println("Location: SOFTWARE\\WOW6432Node\\Microsoft\\Windows\\CurrentVersion\\Uninstall")
+	opts.OutputFormat = strings.ToLower(opts.OutputFormat)
+	if _, ok := outputFormats[opts.OutputFormat]; opts.OutputFormat != "" && !ok {
+		return opts, fmt.Errorf("unknown --output-format %q (valid formats: %s)", opts.OutputFormat, outputFormatList)
+	}
 
-	programs32, err := scanRegistryLocation(`SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`)
+	templateText, _ := cmd.Flags().GetString("template")
+	templateFile, _ := cmd.Flags().GetString("template-file")
+	tmpl, err := parseOutputTemplate(templateText, templateFile)
 	if err != nil {
-		fmt.Printf("Warning: Could not scan 32-bit programs: %v\n", err)
-	} else {
-		fmt.Printf("Found %d 32-bit programs\n", len(programs32))
-		allPrograms = append(allPrograms, programs32...)
+		return opts, err
+	}
+	opts.Template = tmpl
+
+	opts.GroupBy = strings.ToLower(opts.GroupBy)
+	if opts.GroupBy != "" && opts.GroupBy != "source" && opts.GroupBy != "publisher" {
+		return opts, fmt.Errorf("unknown --group-by value %q (valid values: source, publisher)", opts.GroupBy)
 	}
 
-	return allPrograms, nil
+	return opts, nil
 }
 
-// scanRegistryLocation opens a registry key and scans all its subkeys
-// Each subkey represents one installed program
-func scanRegistryLocation(keyPath string) ([]Program, error) {
-	var programs []Program
+// userUninstallKey is where per-user ("just for me") installs register themselves
+const userUninstallKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+// Source values describe which registry location a program was found in
+const (
+	sourceHKLM64 = "HKLM 64-bit"
+	sourceHKLM32 = "HKLM WOW6432Node"
+	sourceHKCU   = "HKCU"
+	sourceAppX   = "Microsoft Store"
+)
+
+// skippedEntry records a registry entry that could not be turned into a Program
+type skippedEntry struct {
+	Location    string // Registry location that was being scanned
+	Subkey      string // Subkey name ("" when the whole location failed)
+	Reason      string // Why it was skipped
+	MissingName bool   // True when the entry simply has no DisplayName
 
-	// Step 1: Open the registry key
-	// registry.OpenKey() is much simpler than raw Windows API calls!
-	// It handles all the UTF-16 conversion and error handling for us
-	fmt.Printf("  Opening registry key: %s\n", keyPath)
-	key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE)
+	AccessDenied bool // True when Windows refused access (running without admin rights)
+}
+
+// isAccessDenied reports whether err is (or wraps) ERROR_ACCESS_DENIED
+func isAccessDenied(err error) bool {
+	return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+}
+
+// appxPackage is one package from Get-AppxPackage, as converted to JSON
+type appxPackage struct {
+	Name            string
+	Version         string
+	InstallLocation string
+}
+
+// appxCommand lists the current user's Store packages as JSON
+// Framework packages (runtimes like VCLibs) are left out, like the Uninstall keys' system components
+const appxCommand = "Get-AppxPackage | Where-Object { -not $_.IsFramework } | Select-Object Name,Version,InstallLocation | ConvertTo-Json"
+
+// scanAppXPackages lists Microsoft Store (AppX) packages by asking PowerShell
+// There's no simple registry location for these, so we use Get-AppxPackage
+func scanAppXPackages() ([]Program, error) {
+	// Step 1: Make sure PowerShell is there (it can be removed or blocked by policy)
+	_, err := exec.LookPath("powershell")
 	if err != nil {
-		return nil, fmt.Errorf("failed to open registry key: %v", err)
+		return nil, fmt.Errorf("PowerShell was not found: %v", err)
 	}
-	defer key.Close() // Always close the key when done
 
-	// Step 2: Get all subkey names
-	// registry.ReadSubKeyNames() does all the enumeration work for us
-	fmt.Printf("  Reading subkey names...\n")
-	subkeyNames, err := key.ReadSubKeyNames(-1) // -1 means read all subkeys
+	// Step 2: Run Get-AppxPackage
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", appxCommand).Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read subkey names: %v", err)
+		return nil, fmt.Errorf("Get-AppxPackage failed: %v", err)
 	}
 
-	fmt.Printf("  Found %d subkeys to process\n", len(subkeyNames))
+	// Step 3: Parse the JSON
+	// ConvertTo-Json writes a single object (not an array) when there's one package
+	var packages []appxPackage
+	trimmed := bytes.TrimSpace(output)
+	switch {
+	case len(trimmed) == 0:
+		return nil, nil
+	case trimmed[0] == '[':
+		err = json.Unmarshal(trimmed, &packages)
+	default:
+		var single appxPackage
+		err = json.Unmarshal(trimmed, &single)
+		packages = append(packages, single)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Get-AppxPackage output: %v", err)
+	}
 
-	// Step 3: Process each subkey (each subkey = one program)
-	for i, subkeyName := range subkeyNames {
-		// Show progress every 50 programs
-		if i%50 == 0 && i > 0 {
-			fmt.Printf("  Processed %d/%d programs...\n", i, len(subkeyNames))
+	// Step 4: Turn the packages into programs
+	var programs []Program
+	for _, pkg := range packages {
+		if pkg.Name == "" {
+			continue
 		}
+		programs = append(programs, Program{
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Path:    pkg.InstallLocation,
+			Source:  sourceAppX,
+			Scope:   scopeUser, // Get-AppxPackage lists the current user's packages
+		})
+	}
 
-		// Get program info from this subkey
-		program, err := getProgramFromSubkey(key, subkeyName)
-		if err != nil {
-			// Skip programs that can't be read (some are system components)
-			continue
+	return programs, nil
+}
+
+// rootName returns the short name of a registry root key, e.g. "HKLM"
+func rootName(root registry.Key) string {
+	switch root {
+	case registry.LOCAL_MACHINE:
+		return "HKLM"
+	case registry.CURRENT_USER:
+		return "HKCU"
+	}
+	return "?"
+}
+
+// errMissingName is returned by Scanner.getProgramFromSubkey for entries without a DisplayName
+var errMissingName = errors.New("no DisplayName value")
+
+// parseIconPath turns a DisplayIcon value into a plain file path
+// Values look like `C:\App\app.exe,0` or `"C:\App\app.exe",-101`, so the
+// quotes and the icon index after the last comma are removed
+func parseIconPath(value string) string {
+	value = strings.TrimSpace(value)
+
+	// A quoted path ends at the closing quote; anything after it is the index
+	if strings.HasPrefix(value, `"`) {
+		end := strings.Index(value[1:], `"`)
+		if end < 0 {
+			return strings.TrimSpace(value[1:])
 		}
+		return strings.TrimSpace(value[1 : end+1])
+	}
 
-		// Only add programs that have a name (some entries are just metadata)
-		if program.Name != "" {
-			programs = append(programs, program)
+	// Only strip the part after the comma if it's a number, since
+	// folder names can contain commas too
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		_, err := strconv.Atoi(strings.TrimSpace(value[i+1:]))
+		if err == nil {
+			value = value[:i]
 		}
 	}
+	return strings.TrimSpace(value)
+}
 
-	return programs, nil
+// installDateLayouts are the InstallDate formats seen in the wild
+// YYYYMMDD is the documented one; the others come from non-conforming installers
+var installDateLayouts = []string{"20060102", "2006-01-02", "2006/01/02", "1/2/2006"}
+
+// parseInstallDate parses a registry InstallDate value (normally YYYYMMDD)
+// Dates are kept in UTC so JSON shows them as e.g. "2024-03-12T00:00:00Z"
+// It returns nil for empty or malformed values instead of failing the scan
+func parseInstallDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range installDateLayouts {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return &date
+		}
+	}
+	return nil
 }
 
-// getProgramFromSubkey reads program details from a specific registry subkey
-// This function extracts the DisplayName, DisplayVersion, and InstallLocation
-func getProgramFromSubkey(parentKey registry.Key, subkeyName string) (Program, error) {
-	var program Program
+// installedLabel returns the install date for display ("2024-03-12"),
+// the raw registry value if it couldn't be parsed, or "" if there is none
+func installedLabel(program Program) string {
+	if program.InstallDate != nil {
+		return program.InstallDate.Format("2006-01-02")
+	}
+	return program.InstallDateRaw
+}
 
-	// Step 1: Open the subkey
-	// This opens the specific program's registry entry
-	subkey, err := registry.OpenKey(parentKey, subkeyName, registry.QUERY_VALUE)
-	if err != nil {
-		return program, err
+// formatAge turns a date into a friendly relative age like "installed 3 months ago"
+func formatAge(date time.Time, now time.Time) string {
+	days := int(now.Sub(date).Hours() / 24)
+
+	switch {
+	case days < 1:
+		return "installed today"
+	case days == 1:
+		return "installed yesterday"
+	case days < 14:
+		return fmt.Sprintf("installed %d days ago", days)
+	case days < 60:
+		return fmt.Sprintf("installed %d weeks ago", days/7)
+	case days < 730:
+		return fmt.Sprintf("installed %d months ago", days/30)
+	default:
+		return fmt.Sprintf("installed %d years ago", days/365)
 	}
-	defer subkey.Close()
+}
 
-	// Step 2: Read the DisplayName
-	// This is the name you see in "Add or Remove Programs"
-	name, _, err := subkey.GetStringValue("DisplayName")
-	if err != nil {
-		// Some programs don't have a DisplayName, skip them
-		return program, err
+// programAge describes how long ago a program was installed
+// InstallDate is preferred; the registry key's last-write time is the fallback
+func programAge(program Program, now time.Time) string {
+	if program.InstallDate != nil {
+		return formatAge(*program.InstallDate, now)
+	}
+	if program.LastWriteTime != nil {
+		return formatAge(*program.LastWriteTime, now) + " (registry last modified)"
+	}
+	return "unknown age"
+}
+
+// totalSizeKB adds up the sizes of the programs; unknown sizes count as 0
+func totalSizeKB(programs []Program) uint64 {
+	var total uint64
+	for _, program := range programs {
+		total += program.SizeKB
 	}
-	program.Name = strings.TrimSpace(name) // Remove extra whitespace
+	return total
+}
 
-	// Step 3: Read the DisplayVersion (optional)
-	// Not all programs have this, so we ignore errors
-	version, _, err := subkey.GetStringValue("DisplayVersion")
-	if err == nil {
-		program.Version = strings.TrimSpace(version)
+// formatSize turns a size in kilobytes into a human-readable string like "1.2 GB"
+// When raw is true the plain kilobyte count is returned instead (for scripts)
+func formatSize(sizeKB uint64, raw bool) string {
+	if raw {
+		return fmt.Sprintf("%d", sizeKB)
 	}
 
-	// Step 4: Read the InstallLocation (optional)
-	// This is where the program is installed
-	path, _, err := subkey.GetStringValue("InstallLocation")
-	if err == nil {
-		program.Path = strings.TrimSpace(path)
+	units := []string{"KB", "MB", "GB", "TB"}
+	size := float64(sizeKB)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", sizeKB, units[0])
+	}
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
+
+// sizeLabel returns the label used in front of a size value
+// Raw sizes are labelled "SizeKB" so the unit is clear without a suffix
+func sizeLabel(raw bool) string {
+	if raw {
+		return "SizeKB"
 	}
+	return "Size"
+}
 
-	return program, nil
+// totalSizeLine is the summary line with the size of all programs together
+// With --raw-sizes the total is a kilobyte count too, and the label names the unit
+func totalSizeLine(programs []Program, raw bool) string {
+	label := "Total estimated size on disk"
+	if raw {
+		label += " (KB)"
+	}
+	return fmt.Sprintf("%s: %s", label, formatSize(totalSizeKB(programs), raw))
 }
 
 // displayResults formats and displays the scan results
-func displayResults(programs []Program) {
-	fmt.Printf("\n" + strings.Repeat("=", 50) + "\n")
+func displayResults(programs []Program, opts outputOptions) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
 	fmt.Printf("SCAN COMPLETE!\n")
-	fmt.Printf("Found %d installed programs:\n", len(programs))
-	fmt.Printf(strings.Repeat("=", 50) + "\n\n")
+	if opts.TotalFound > len(programs) {
+		fmt.Printf("Showing %d of %d installed programs:\n", len(programs), opts.TotalFound)
+	} else {
+		fmt.Printf("Found %d installed programs:\n", len(programs))
+	}
+	fmt.Println(totalSizeLine(programs, opts.RawSizes))
+	if opts.DuplicatesRemoved > 0 {
+		fmt.Printf("(%d duplicate entries from the 32-bit and 64-bit views were merged)\n", opts.DuplicatesRemoved)
+	}
+	if opts.SystemHidden > 0 {
+		fmt.Printf("(%d system components were hidden; use --include-system to show them)\n", opts.SystemHidden)
+	}
+	if opts.UpdatesHidden > 0 {
+		fmt.Printf("(%d Windows updates were hidden; use --include-updates to show them)\n", opts.UpdatesHidden)
+	}
+	if opts.Excluded > 0 {
+		fmt.Printf("(%d programs were excluded by --exclude)\n", opts.Excluded)
+	}
+	if opts.NoSize > 0 {
+		fmt.Printf("(%d programs without a size were left out by --min-size)\n", opts.NoSize)
+	}
+	if opts.NoDate > 0 {
+		fmt.Printf("(%d programs without an install date were left out by --modified-since)\n", opts.NoDate)
+	}
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	now := time.Now()
+
+	// Grouped display: a heading with a count, then that group's programs
+	var groups []programGroup
+	switch opts.GroupBy {
+	case "source":
+		groups = groupPrograms(programs, func(p Program) string { return p.Source })
+	case "publisher":
+		groups = groupByPublisher(programs)
+	}
+	if groups != nil {
+		for _, group := range groups {
+			fmt.Printf("%s (%d programs)\n", group.Name, len(group.Programs))
+			fmt.Printf("%s\n\n", strings.Repeat("-", 50))
+			for i, program := range group.Programs {
+				displayProgram(i+1, program, opts, now)
+			}
+		}
+		return
+	}
 
 	// Display each program with nice formatting
 	for i, program := range programs {
-		fmt.Printf("%d. %s", i+1, program.Name)
+		displayProgram(i+1, program, opts, now)
+	}
+}
 
-		// Add version if available
-		if program.Version != "" {
-			fmt.Printf(" (v%s)", program.Version)
+// displayProgram prints one numbered program entry with its details
+func displayProgram(number int, program Program, opts outputOptions, now time.Time) {
+	fmt.Printf("%d. %s", number, program.Name)
+
+	// Add version if available
+	if program.Version != "" {
+		fmt.Printf(" (v%s)", program.Version)
+	}
+	fmt.Println()
+
+	// Add installation path if available
+	if program.Path != "" {
+		fmt.Printf("   Path: %s\n", program.Path)
+	}
+
+	// Add publisher if available
+	if program.Publisher != "" {
+		fmt.Printf("   Publisher: %s\n", program.Publisher)
+	}
+
+	// Add architecture if known
+	if program.Architecture != "" {
+		fmt.Printf("   Architecture: %s\n", program.Architecture)
+	}
+
+	// Add install date if available
+	if installed := installedLabel(program); installed != "" {
+		fmt.Printf("   Installed: %s\n", installed)
+	}
+
+	// Add size if available
+	if program.SizeKB > 0 {
+		fmt.Printf("   %s: %s\n", sizeLabel(opts.RawSizes), formatSize(program.SizeKB, opts.RawSizes))
+	}
+
+	// Add relative age if requested
+	if opts.Age {
+		fmt.Printf("   Age: %s\n", programAge(program, now))
+	}
+
+	// Add the links and uninstall commands in verbose mode (they're long and mostly noise)
+	if opts.Verbose {
+		if program.URLInfoAbout != "" {
+			fmt.Printf("   Homepage: %s\n", program.URLInfoAbout)
+		}
+		if program.HelpLink != "" {
+			fmt.Printf("   Support: %s\n", program.HelpLink)
+		}
+		if program.UninstallString != "" {
+			fmt.Printf("   Uninstall: %s\n", program.UninstallString)
+		}
+		if program.QuietUninstallString != "" {
+			fmt.Printf("   Quiet uninstall: %s\n", program.QuietUninstallString)
+		}
+		if program.ModifyPath != "" {
+			fmt.Printf("   Modify: %s\n", program.ModifyPath)
 		}
-		fmt.Println()
+		if program.RepairString != "" {
+			fmt.Printf("   Repair: %s\n", program.RepairString)
+		}
+		if program.MSIProductCode != "" {
+			fmt.Printf("   MSI ProductCode: %s\n", program.MSIProductCode)
+		}
+		if program.BundleUpgradeCode != "" {
+			fmt.Printf("   Bundle UpgradeCode: %s\n", program.BundleUpgradeCode)
+		}
+	}
+	fmt.Println()
+}
 
-		// Add installation path if available
-		if program.Path != "" {
-			fmt.Printf("   Path: %s\n", program.Path)
+// programGroup is a named set of programs used by the grouped display
+type programGroup struct {
+	Name     string
+	Programs []Program
+}
+
+// groupPrograms splits programs into groups by the given key
+// Groups keep the order in which they were first seen (i.e. scan order)
+func groupPrograms(programs []Program, key func(Program) string) []programGroup {
+	var groups []programGroup
+	index := make(map[string]int)
+
+	for _, program := range programs {
+		name := key(program)
+		if name == "" {
+			name = "Unknown"
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, programGroup{Name: name})
+		}
+		groups[i].Programs = append(groups[i].Programs, program)
+	}
+
+	return groups
+}
+
+// groupByPublisher groups programs under their publisher, publishers in
+// alphabetical order and programs sorted by name within each group
+// Programs without a publisher go in an "Unknown Publisher" group at the end
+func groupByPublisher(programs []Program) []programGroup {
+	var known []programGroup
+	var unknown programGroup
+	index := make(map[string]int)
+
+	for _, program := range programs {
+		publisher := strings.TrimSpace(program.Publisher)
+		if publisher == "" {
+			unknown.Programs = append(unknown.Programs, program)
+			continue
+		}
+
+		i, ok := index[publisher]
+		if !ok {
+			i = len(known)
+			index[publisher] = i
+			known = append(known, programGroup{Name: publisher})
+		}
+		known[i].Programs = append(known[i].Programs, program)
+	}
+
+	sort.SliceStable(known, func(i, j int) bool {
+		return strings.ToLower(known[i].Name) < strings.ToLower(known[j].Name)
+	})
+	if len(unknown.Programs) > 0 {
+		unknown.Name = "Unknown Publisher"
+		known = append(known, unknown)
+	}
+	for _, group := range known {
+		sortPrograms(group.Programs, "name")
+	}
+
+	return known
+}
+
+// outputFormats are the values accepted by --output-format, with the name
+// used in messages such as "Results saved to JSON: programs.json"
+var outputFormats = map[string]string{
+	"json":     "JSON",
+	"text":     "text",
+	"csv":      "CSV",
+	"yaml":     "YAML",
+	"xml":      "XML",
+	"markdown": "Markdown",
+	"html":     "HTML",
+	"sqlite":   "SQLite",
+	"ndjson":   "NDJSON",
+}
+
+// outputFormatList is the --output-format values in a fixed order, for help and errors
+const outputFormatList = "json, ndjson, text, csv, yaml, xml, markdown, html, sqlite"
+
+// stdoutName is the --output value that means "write to the screen instead of a file"
+const stdoutName = "-"
+
+// formatFromExtension picks an output format from a file name
+// It returns false for extensions WinClone doesn't know, so "programs.xlsx"
+// isn't quietly written as plain text under a misleading name
+func formatFromExtension(filename string) (string, bool) {
+	if filename == stdoutName {
+		return "text", true
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return "json", true
+	case ".csv":
+		return "csv", true
+	case ".yaml", ".yml":
+		return "yaml", true
+	case ".html", ".htm":
+		return "html", true
+	case ".md":
+		return "markdown", true
+	case ".xml":
+		return "xml", true
+	case ".db", ".sqlite":
+		return "sqlite", true
+	case ".ndjson", ".jsonl":
+		return "ndjson", true
+	case ".txt", ".text", ".log":
+		return "text", true
+	}
+	return "", false
+}
+
+// outputFormatFor returns the format filename will be saved in:
+// the --output-format value if given, otherwise the one its extension names
+func outputFormatFor(filename, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	format, ok := formatFromExtension(filename)
+	if !ok {
+		kind := fmt.Sprintf("unknown output file type %q", filepath.Ext(filename))
+		if filepath.Ext(filename) == "" {
+			kind = fmt.Sprintf("output file %q has no extension", filename)
+		}
+		return "", fmt.Errorf("%s (use .json, .ndjson, .jsonl, .csv, .yaml, .xml, .md, .html, .db or .txt, or pick the format with --output-format)", kind)
+	}
+	return format, nil
+}
+
+// saveResults saves the program list to a file
+// The format comes from --output-format if given, otherwise from the file extension
+// A filename of "-" writes to stdout, so the output can be piped
+func saveResults(programs []Program, filename string, opts outputOptions) error {
+	format, err := outputFormatFor(filename, opts.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	if opts.Append {
+		err = appendResults(programs, filename, format, opts)
+	} else {
+		switch format {
+		case "json":
+			err = saveToJSON(programs, filename, opts)
+		case "csv":
+			err = saveToCSV(programs, filename)
+		case "yaml":
+			err = saveToYAML(programs, filename)
+		case "html":
+			err = saveToHTML(programs, filename)
+		case "markdown":
+			err = saveToMarkdown(programs, filename)
+		case "xml":
+			err = saveToXML(programs, filename)
+		case "sqlite":
+			err = saveToSQLite(programs, filename)
+		case "ndjson":
+			err = saveToNDJSON(programs, filename)
+		default:
+			err = saveToText(programs, filename, opts)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %v", outputFormats[format], err)
+	}
+
+	if !opts.Quiet && filename != stdoutName {
+		verb := "saved"
+		if opts.Append {
+			verb = "appended"
+		}
+		fmt.Printf("\nResults %s to %s: %s\n", verb, outputFormats[format], filename)
+	}
+	return nil
+}
+
+// createOutput starts saving results to a file, or returns stdout for "-"
+// Writes go to a temporary file in the same directory, and Commit renames it
+// over filename, so a crash or Ctrl+C never leaves a truncated file behind.
+// Callers defer Close, which throws the temporary file away unless Commit was called
+func createOutput(filename string) (*outputFile, error) {
+	if filename == stdoutName {
+		return &outputFile{Writer: os.Stdout}, nil
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	addPendingOutput(temp.Name())
+	return &outputFile{Writer: temp, temp: temp, name: filename}, nil
+}
+
+// pendingOutputs are the temporary files createOutput hasn't committed or removed yet
+// While there are any, Ctrl+C removes them before exiting, so an interrupted
+// save doesn't leave name.*.tmp files behind
+var pendingOutputs = struct {
+	sync.Mutex
+	names     map[string]bool
+	interrupt chan os.Signal // Receives Ctrl+C while names isn't empty, nil otherwise
+}{names: map[string]bool{}}
+
+// addPendingOutput records a new temporary file, catching Ctrl+C if it's the first
+func addPendingOutput(name string) {
+	pendingOutputs.Lock()
+	defer pendingOutputs.Unlock()
+
+	pendingOutputs.names[name] = true
+	if pendingOutputs.interrupt == nil {
+		pendingOutputs.interrupt = make(chan os.Signal, 1)
+		signal.Notify(pendingOutputs.interrupt, os.Interrupt)
+		go removePendingOnInterrupt(pendingOutputs.interrupt)
+	}
+}
+
+// removePendingOutput forgets a temporary file that was committed or removed
+// Once none are left, Ctrl+C goes back to its normal behaviour
+func removePendingOutput(name string) {
+	pendingOutputs.Lock()
+	defer pendingOutputs.Unlock()
+
+	delete(pendingOutputs.names, name)
+	if len(pendingOutputs.names) == 0 && pendingOutputs.interrupt != nil {
+		signal.Stop(pendingOutputs.interrupt)
+		close(pendingOutputs.interrupt)
+		pendingOutputs.interrupt = nil
+	}
+}
+
+// removePendingOnInterrupt waits for Ctrl+C, then removes the temporary files and exits
+// It returns without doing anything when interrupt is closed because the files are gone
+func removePendingOnInterrupt(interrupt chan os.Signal) {
+	_, ok := <-interrupt
+	if !ok {
+		return
+	}
+
+	pendingOutputs.Lock()
+	for name := range pendingOutputs.names {
+		os.Remove(name)
+	}
+	pendingOutputs.Unlock()
+
+	fmt.Fprintln(os.Stderr, "Interrupted - the output file was left unchanged")
+	os.Exit(1)
+}
+
+// outputFile is a file being saved by createOutput
+type outputFile struct {
+	io.Writer
+	temp *os.File // The temporary file (nil for stdout)
+	name string   // The file it replaces on Commit
+	done bool     // Commit or Close has already run
+}
+
+// Commit finishes the file and moves it into place
+// For stdout it does nothing
+func (f *outputFile) Commit() error {
+	if f.temp == nil || f.done {
+		return nil
+	}
+	f.done = true
+	defer removePendingOutput(f.temp.Name())
+
+	err := f.temp.Close()
+	if err != nil {
+		os.Remove(f.temp.Name())
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	err = os.Rename(f.temp.Name(), f.name)
+	if err != nil {
+		os.Remove(f.temp.Name())
+		return fmt.Errorf("failed to replace file: %v", err)
+	}
+	return nil
+}
+
+// Close throws the temporary file away if Commit wasn't called, leaving any
+// earlier file untouched; stdout stays open for later output
+func (f *outputFile) Close() error {
+	if f.temp == nil || f.done {
+		return nil
+	}
+	f.done = true
+	defer removePendingOutput(f.temp.Name())
+
+	f.temp.Close()
+	return os.Remove(f.temp.Name())
+}
+
+// printFormats prints the results to stdout in one or more formats
+// A comma-separated list like "json,text" prints each format in turn,
+// separated by a delimiter line so the sections are easy to tell apart
+func printFormats(programs []Program, format string, opts outputOptions) error {
+	formats := strings.Split(strings.ToLower(format), ",")
+
+	// Check every format first so we don't print half the output and then fail
+	for i, f := range formats {
+		formats[i] = strings.TrimSpace(f)
+		if formats[i] != "text" && formats[i] != "json" && formats[i] != "markdown" && formats[i] != "table" {
+			return fmt.Errorf("unknown format %q (valid formats: text, table, json, markdown)", formats[i])
+		}
+	}
+
+	for i, f := range formats {
+		// Only add delimiters when more than one format was asked for
+		if len(formats) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s %s %s\n", strings.Repeat("-", 20), strings.ToUpper(f), strings.Repeat("-", 20))
+		}
+
+		switch f {
+		case "json":
+			err := writeJSON(os.Stdout, programs, opts)
+			if err != nil {
+				return err
+			}
+		case "markdown":
+			err := writeMarkdown(os.Stdout, programs)
+			if err != nil {
+				return err
+			}
+		case "table":
+			err := writeTable(os.Stdout, programs, opts.Width)
+			if err != nil {
+				return err
+			}
+		case "text":
+			displayResults(programs, opts)
 		}
-		fmt.Println()
 	}
+
+	return nil
 }
 
 // saveToJSON saves the program list to a JSON file
-func saveToJSON(programs []Program, filename string) error {
+// With --wrap the list is stored inside a ScanResult with schema details
+func saveToJSON(programs []Program, filename string, opts outputOptions) error {
 	// Create the JSON file
-	file, err := os.Create(filename)
+	file, err := createOutput(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %v", err)
 	}
-	defer file.Close()
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	err = writeJSON(file, programs, opts)
+	if err != nil {
+		return err
+	}
+	return file.Commit()
+}
 
+// writeJSON writes the program list as indented JSON to any writer
+// This is shared by file output and stdout output
+func writeJSON(w io.Writer, programs []Program, opts outputOptions) error {
 	// Create JSON encoder
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
 
-	// Encode the programs slice to JSON
-	err = encoder.Encode(programs)
+	// Encode the programs slice (or the wrapped result) to JSON
+	var err error
+	if opts.Wrap {
+		err = encoder.Encode(newScanResult(programs, opts.Label))
+	} else {
+		err = encoder.Encode(programs)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to encode JSON: %v", err)
 	}
@@ -248,19 +1500,332 @@ func saveToJSON(programs []Program, filename string) error {
 	return nil
 }
 
+// saveToCSV saves the program list to a CSV file (for Excel and friends)
+// encoding/csv takes care of quoting values that contain commas or quotes
+func saveToCSV(programs []Program, filename string) error {
+	// Create the CSV file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	err = writeCSV(file, programs)
+	if err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// writeCSV writes the header row and one row per program to any writer
+func writeCSV(w io.Writer, programs []Program) error {
+	writer := csv.NewWriter(w)
+
+	// Write the header row, then one row per program
+	err := writer.Write([]string{"Name", "Version", "Path", "Publisher", "Architecture", "MSIProductCode", "BundleUpgradeCode",
+		"ModifyPath", "RepairString"})
+	if err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	for _, program := range programs {
+		// Missing values are just empty strings, so they become empty cells
+		err = writer.Write([]string{program.Name, program.Version, program.Path, program.Publisher, program.Architecture,
+			program.MSIProductCode, program.BundleUpgradeCode, program.ModifyPath, program.RepairString})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %v", err)
+		}
+	}
+
+	// Flush buffered rows to the file and report any write error
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	return nil
+}
+
+// saveToMarkdown saves the program list as a Markdown table (for wikis and docs)
+func saveToMarkdown(programs []Program, filename string) error {
+	// Create the Markdown file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	err = writeMarkdown(file, programs)
+	if err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// writeMarkdown writes the program list as a GitHub-flavored Markdown table
+// A title and the generation time come first so the page explains itself
+// Missing values become empty cells so every row has the same columns
+func writeMarkdown(w io.Writer, programs []Program) error {
+	// Write the title and timestamp
+	_, err := fmt.Fprintf(w, "# Installed Programs\n\n")
+	if err != nil {
+		return fmt.Errorf("failed to write Markdown: %v", err)
+	}
+	fmt.Fprintf(w, "Generated on %s (%d programs)\n\n", time.Now().Format("2006-01-02 15:04:05"), len(programs))
+
+	// Write the header row and the separator line under it
+	fmt.Fprintf(w, "| Name | Version | Publisher | Architecture | Path |\n")
+	fmt.Fprintf(w, "| --- | --- | --- | --- | --- |\n")
+
+	for _, program := range programs {
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+			markdownCell(program.Name), markdownCell(program.Version), markdownCell(program.Publisher),
+			markdownCell(program.Architecture), markdownCell(program.Path))
+	}
+
+	return nil
+}
+
+// writeTable writes Name, Version and Publisher as aligned columns under a header row
+// Long values are cut with "..." so each row fits in width characters;
+// a width of 0 or less never cuts anything
+func writeTable(w io.Writer, programs []Program, width int) error {
+	// Version and publisher get what they need up to a cap, the name gets the rest
+	versionWidth, publisherWidth := len("Version"), len("Publisher")
+	for _, program := range programs {
+		versionWidth = max(versionWidth, utf8.RuneCountInString(program.Version))
+		publisherWidth = max(publisherWidth, utf8.RuneCountInString(program.Publisher))
+	}
+	nameWidth := 0 // No limit
+	if width > 0 {
+		versionWidth = min(versionWidth, 16)
+		publisherWidth = min(publisherWidth, max(width/4, len("Publisher")))
+		// One column is left spare: a full-width line makes the Windows console wrap
+		nameWidth = max(width-versionWidth-publisherWidth-2*tableGap-1, 10)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, tableGap, ' ', 0)
+	fmt.Fprintf(tw, "Name\tVersion\tPublisher\n")
+	fmt.Fprintf(tw, "----\t-------\t---------\n")
+	for _, program := range programs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n",
+			truncate(program.Name, nameWidth), truncate(program.Version, versionWidth), truncate(program.Publisher, publisherWidth))
+	}
+	return tw.Flush()
+}
+
+// tableGap is the number of spaces between table columns
+const tableGap = 2
+
+// truncate shortens text to at most limit characters, ending in "..." when cut
+// A limit of 0 or less leaves the text alone
+func truncate(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ") // Tabs or newlines would break the columns
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	if limit <= 3 {
+		return string(runes[:limit])
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// markdownCell escapes a value for a Markdown table cell
+// A "|" would start a new column and a line break would end the row
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// saveToYAML saves the program list to a YAML file (for Ansible, Salt and friends)
+// Keys are snake_case and missing values are left out, so conditionals like
+// "when: item.path is defined" work as expected
+func saveToYAML(programs []Program, filename string) error {
+	// Create the YAML file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	// Encode the programs slice as a YAML sequence
+	encoder := yaml.NewEncoder(file)
+	encoder.SetIndent(2)
+	err = encoder.Encode(programs)
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML: %v", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML: %v", err)
+	}
+
+	return file.Commit()
+}
+
+// xmlPrograms is the root <Programs> element of the XML output
+// Each program becomes a <Program> child with one element per field, which
+// PowerShell can read directly: ([xml](Get-Content programs.xml)).Programs.Program
+type xmlPrograms struct {
+	XMLName  xml.Name  `xml:"Programs"`
+	Count    int       `xml:"count,attr"`
+	Programs []Program `xml:"Program"`
+}
+
+// saveToXML saves the program list to an XML file
+func saveToXML(programs []Program, filename string) error {
+	// Create the XML file
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	// Write the <?xml ...?> declaration, then the indented document
+	_, err = io.WriteString(file, xml.Header)
+	if err != nil {
+		return fmt.Errorf("failed to write XML: %v", err)
+	}
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(xmlPrograms{Count: len(programs), Programs: programs})
+	if err != nil {
+		return fmt.Errorf("failed to encode XML: %v", err)
+	}
+
+	// End the file with a newline like the other formats
+	_, err = io.WriteString(file, "\n")
+	if err != nil {
+		return fmt.Errorf("failed to write XML: %v", err)
+	}
+
+	return file.Commit()
+}
+
+// loadScanFile reads a JSON file written by saveToJSON
+// Both the bare array and the wrapped ScanResult layouts are accepted;
+// a bare array is returned as a ScanResult with only Programs filled in
+// A file collected with --output-append (an array of wrapped scans) gives its
+// last, most recent scan
+// Wrapped files from a newer, incompatible schema are rejected with an error
+func loadScanFile(filename string) (ScanResult, error) {
+	var result ScanResult
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return result, err
+	}
+
+	// A bare array starts with "[", a wrapped result with "{"
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		trimmed, err = lastAppendedScan(trimmed)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse %s: %v", filename, err)
+		}
+	}
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &result.Programs)
+	} else {
+		err = json.Unmarshal(trimmed, &result)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to parse %s: %v", filename, err)
+	}
+
+	// A wrapped file must say which layout it uses, or it isn't one of ours
+	if trimmed[0] == '{' && result.SchemaVersion == 0 {
+		return result, fmt.Errorf("%s is not a WinClone scan (no schemaVersion)", filename)
+	}
+
+	// A newer file may hold fields or meanings this build doesn't know,
+	// so it can't be read correctly; refuse it rather than guess
+	if result.SchemaVersion > schemaVersion {
+		return result, fmt.Errorf("%s uses schema version %d, but WinClone %s only reads up to version %d; upgrade WinClone to read it",
+			filename, result.SchemaVersion, winCloneVersion, schemaVersion)
+	}
+
+	return result, nil
+}
+
+// lastAppendedScan tells an array of wrapped scans (from --output-append) apart
+// from a bare program array by the "programs" key of its elements
+// For appended scans it returns the last one; anything else is returned unchanged
+func lastAppendedScan(data []byte) ([]byte, error) {
+	var elements []json.RawMessage
+	err := json.Unmarshal(data, &elements)
+	if err != nil || len(elements) == 0 {
+		return data, err
+	}
+
+	var envelope struct {
+		Programs json.RawMessage `json:"programs"`
+	}
+	err = json.Unmarshal(elements[0], &envelope)
+	if err != nil || envelope.Programs == nil {
+		return data, nil // A program has no "programs" key, so this is a bare array
+	}
+	return bytes.TrimSpace(elements[len(elements)-1]), nil
+}
+
+// isAppendedScan reports whether data is an array of wrapped scans (from --output-append)
+// rather than a single scan or a bare program array
+func isAppendedScan(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return false
+	}
+	last, err := lastAppendedScan(trimmed)
+	return err == nil && len(last) > 0 && last[0] == '{'
+}
+
 // saveToText saves the program list to a text file
-func saveToText(programs []Program, filename string) error {
+func saveToText(programs []Program, filename string, opts outputOptions) error {
 	// Create the text file
-	file, err := os.Create(filename)
+	file, err := createOutput(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %v", err)
 	}
-	defer file.Close()
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	writeText(file, programs, opts)
+	return file.Commit()
+}
 
+// writeText writes the text report, a header with the totals and then each program
+func writeText(file io.Writer, programs []Program, opts outputOptions) {
 	// Write header
 	fmt.Fprintf(file, "WinClone - Installed Programs List\n")
-	fmt.Fprintf(file, "Generated on: %s\n", "2025-01-14") // You could use time.Now() here
-	fmt.Fprintf(file, "Total programs found: %d\n", len(programs))
+	fmt.Fprintf(file, "Generated on: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	hostname, err := os.Hostname()
+	if err == nil {
+		fmt.Fprintf(file, "Computer: %s\n", hostname) // Reports get shared, so say which machine this is
+	}
+	if opts.TotalFound > len(programs) {
+		fmt.Fprintf(file, "Showing %d of %d programs found\n", len(programs), opts.TotalFound)
+	} else {
+		fmt.Fprintf(file, "Total programs found: %d\n", len(programs))
+	}
+	fmt.Fprintln(file, totalSizeLine(programs, opts.RawSizes))
+	if opts.DuplicatesRemoved > 0 {
+		fmt.Fprintf(file, "Duplicate entries merged: %d\n", opts.DuplicatesRemoved)
+	}
+	if opts.SystemHidden > 0 {
+		fmt.Fprintf(file, "System components hidden: %d\n", opts.SystemHidden)
+	}
+	if opts.UpdatesHidden > 0 {
+		fmt.Fprintf(file, "Windows updates hidden: %d\n", opts.UpdatesHidden)
+	}
+	if opts.Excluded > 0 {
+		fmt.Fprintf(file, "Programs excluded: %d\n", opts.Excluded)
+	}
+	if opts.NoSize > 0 {
+		fmt.Fprintf(file, "Programs without a size (left out by --min-size): %d\n", opts.NoSize)
+	}
+	if opts.NoDate > 0 {
+		fmt.Fprintf(file, "Programs without an install date (left out by --modified-since): %d\n", opts.NoDate)
+	}
 	fmt.Fprintf(file, "%s\n\n", strings.Repeat("=", 50))
 
 	// Write each program
@@ -277,10 +1842,28 @@ func saveToText(programs []Program, filename string) error {
 		if program.Path != "" {
 			fmt.Fprintf(file, "   Path: %s\n", program.Path)
 		}
+
+		// Add publisher if available
+		if program.Publisher != "" {
+			fmt.Fprintf(file, "   Publisher: %s\n", program.Publisher)
+		}
+
+		// Add architecture if known
+		if program.Architecture != "" {
+			fmt.Fprintf(file, "   Architecture: %s\n", program.Architecture)
+		}
+
+		// Add install date if available
+		if installed := installedLabel(program); installed != "" {
+			fmt.Fprintf(file, "   Installed: %s\n", installed)
+		}
+
+		// Add size if available
+		if program.SizeKB > 0 {
+			fmt.Fprintf(file, "   %s: %s\n", sizeLabel(opts.RawSizes), formatSize(program.SizeKB, opts.RawSizes))
+		}
 		fmt.Fprintf(file, "\n")
 	}
-
-	return nil
 }
 
 func init() {
@@ -289,5 +1872,149 @@ func init() {
 	rootCmd.AddCommand(scanCmd)
 
 	// Add the --output flag for file export
-	scanCmd.Flags().StringP("output", "o", "", "Save results to file (JSON: .json, Text: .txt)")
+	scanCmd.Flags().StringP("output", "o", "", "Save results to file (JSON: .json, NDJSON: .ndjson, CSV: .csv, HTML: .html, Markdown: .md, YAML: .yaml, XML: .xml, SQLite: .db, Text: .txt)")
+
+	// Add the --raw-sizes flag for machine-friendly size values
+	scanCmd.Flags().Bool("raw-sizes", false, "Show sizes as plain kilobyte integers instead of human-readable values")
+
+	// Add the --filter flag for quick "is X installed?" checks
+	scanCmd.Flags().StringP("filter", "f", "", "Only include programs whose name or publisher contains this text (case-insensitive)")
+
+	// Add the --filter-regex flag for pattern-based filtering
+	// It can't be combined with --filter, since it's unclear how they'd interact
+	scanCmd.Flags().String("filter-regex", "", "Only include programs whose name matches this regular expression")
+	scanCmd.MarkFlagsMutuallyExclusive("filter", "filter-regex")
+
+	// Add the --publisher flag to show only one vendor's software
+	scanCmd.Flags().StringP("publisher", "p", "", "Only include programs whose publisher contains this text (case-insensitive)")
+
+	// Add the --filter-arch flag to show only 32-bit or 64-bit programs
+	// --arch is the same filter under a shorter name
+	scanCmd.Flags().String("filter-arch", "", "Only include programs of one architecture: x64 or x86")
+	scanCmd.Flags().String("arch", "", "Same as --filter-arch")
+	scanCmd.MarkFlagsMutuallyExclusive("filter-arch", "arch")
+
+	// Add the --workers flag to control how many subkeys are read in parallel
+	// --parallel is the same setting under the name most tools use
+	scanCmd.Flags().Int("workers", runtime.NumCPU(), "Number of registry subkeys to read at the same time")
+	scanCmd.Flags().Int("parallel", runtime.NumCPU(), "Same as --workers")
+	scanCmd.MarkFlagsMutuallyExclusive("workers", "parallel")
+
+	// Add the --registry-view flag to read only the 32-bit or 64-bit registry view
+	scanCmd.Flags().String("registry-view", registryViewBoth, "Registry views to scan: both, 32 or 64")
+
+	// Add the --scope flag to pick machine-wide or per-user installs
+	scanCmd.Flags().String("scope", scopeAll, "Which installs to include: machine, user or all")
+
+	// Add the --exclude flag (repeatable) to hide noisy entries
+	scanCmd.Flags().StringArray("exclude", nil, "Hide programs whose name contains this text (repeatable; prefix with re: for a regular expression)")
+
+	// Add the --watch flag to turn the scan into a live monitor
+	scanCmd.Flags().String("watch", "", "Rescan every interval (e.g. 30s) and show what changed, until Ctrl+C")
+
+	// Add the --cache-ttl and --no-cache flags to control reuse of a recent scan
+	scanCmd.Flags().Duration("cache-ttl", 5*time.Minute, "Reuse a cached scan younger than this (e.g. 30s, 10m; 0 to disable)")
+	scanCmd.Flags().Bool("no-cache", false, "Always read the registry instead of using the cached scan")
+
+	// Add the --modified-since flag to find recent installs
+	scanCmd.Flags().String("modified-since", "", "Only include programs installed on or after this date (2024-01-14) or this many days ago (7d)")
+
+	// Add the --min-size flag to find large programs
+	scanCmd.Flags().String("min-size", "", "Only include programs of at least this size, e.g. 500MB or 2GB")
+
+	// Add the --include-store flag for Microsoft Store apps
+	scanCmd.Flags().Bool("include-store", false, "Also list Microsoft Store (AppX) packages, using PowerShell")
+
+	// Add the --include-system flag to show entries Windows hides from Control Panel
+	scanCmd.Flags().Bool("include-system", false, "Include entries marked SystemComponent=1 (hidden from Add or Remove Programs)")
+	scanCmd.Flags().Bool("include-system-components", false, "Same as --include-system")
+	scanCmd.MarkFlagsMutuallyExclusive("include-system", "include-system-components")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(scanCmd)
+
+	// Add the --no-dedup flag for users who care about the 32/64-bit distinction
+	scanCmd.Flags().Bool("no-dedup", false, "Keep programs that appear in both the 64-bit and 32-bit registry views twice")
+
+	// Add the --sort flag to order the results
+	scanCmd.Flags().String("sort", "name", "Sort results by: "+strings.Join(sortKeys, ", "))
+
+	// Add the --output-format flag for when the file extension doesn't say the format
+	scanCmd.Flags().String("output-format", "", "Format for -o, overriding the file extension: "+outputFormatList+" (writes to stdout without -o)")
+
+	// Add the --output-append flag to collect scans in one file over time
+	scanCmd.Flags().Bool("output-append", false, "Append to the -o file (JSON, NDJSON, CSV or text) instead of overwriting it")
+
+	// Add the --stream flag to print NDJSON while the scan is still running
+	scanCmd.Flags().Bool("stream", false, "Print each program as a line of NDJSON as soon as it's read")
+
+	// Add the --template and --template-file flags for custom output formats
+	scanCmd.Flags().String("template", "", "Write results with this Go text/template (sees .Programs and .Meta)")
+	scanCmd.Flags().String("template-file", "", "Like --template, but read the template from a file")
+	scanCmd.MarkFlagsMutuallyExclusive("template", "template-file")
+
+	// Add the --interactive flag to pick programs from a checkbox list
+	scanCmd.Flags().Bool("interactive", false, "Choose the programs to show or save from a checkbox list")
+
+	// Add the --limit flag for a quick look at the top of the list
+	scanCmd.Flags().Int("limit", 0, "Only show or save the first N programs after sorting (0 means no limit)")
+
+	// Add the --format flag for screen output (comma-separated prints several formats)
+	scanCmd.Flags().String("format", "text", "Screen output format: text, table, json or markdown (use \"json,text\" to print both)")
+
+	// Add the --width flag for how wide --format table may get
+	scanCmd.Flags().Int("width", 80, "Line width for --format table; longer values are cut with \"...\" (0 for no limit)")
+
+	// Add the --verbose flag for scan progress and extra details on screen
+	scanCmd.Flags().BoolP("verbose", "v", false, "Show scan progress and extra details such as uninstall commands")
+
+	// Add the --quiet flag for scheduled tasks
+	scanCmd.Flags().BoolP("quiet", "q", false, "Print nothing except errors (use with -o for scheduled scans)")
+
+	// Add the --age flag for relative install ages
+	scanCmd.Flags().Bool("age", false, "Show how long ago each program was installed (screen output only)")
+
+	// Add the --group-by flag for grouped screen output
+	scanCmd.Flags().String("group-by", "", "Group the screen list by a field: source or publisher")
+
+	// Add the --wrap flag; --wrap=false goes back to the bare array for old scripts
+	scanCmd.Flags().Bool("wrap", true, "Wrap JSON output in an object with schemaVersion, winCloneVersion and scan details (--wrap=false for a bare array)")
+	scanCmd.Flags().String("label", "", "Free-text label stored in wrapped JSON (e.g. \"pre-migration baseline\")")
+
+	// Add the --strict flags for high-assurance inventories
+	scanCmd.Flags().Bool("strict", false, "Fail with a report if any registry entry could not be read")
+	scanCmd.Flags().Bool("strict-unnamed", false, "With --strict, also fail on entries that have no DisplayName")
+
+	// Add the --changed-since-cache flag for "what's new since last time"
+	scanCmd.Flags().Bool("changed-since-cache", false, "Print only changes since the previous cached scan, then update the cache")
+
+	// Add the --cross-check flag for the Control Panel comparison
+	scanCmd.Flags().Bool("cross-check", false, "Warn if fewer programs were found than Control Panel would show, and why")
+
+	// Add the --show-errors flag to explain entries that were skipped
+	scanCmd.Flags().Bool("show-errors", false, "Summarize registry entries that couldn't be read, and why")
+
+	// Add the --timing flag to see where a slow scan spends its time
+	scanCmd.Flags().Bool("timing", false, "Print how long each scan phase took (to stderr)")
+
+	// Add the --path-conflicts flag for the install location audit
+	scanCmd.Flags().Bool("path-conflicts", false, "Report install locations shared by or nested inside other programs")
+
+	// Add the --arch-mismatch flag for the architecture audit
+	scanCmd.Flags().Bool("arch-mismatch", false, "Report programs whose install path doesn't match their registry architecture")
+
+	// --stream writes each program to stdout before the list is complete, so the
+	// other outputs and the flags that need the whole list are refused instead
+	// of silently ignored
+	// This runs last: cobra panics if a flag isn't defined yet when it's marked
+	for _, flag := range []string{
+		"output", "watch", "interactive",
+		"filter", "filter-regex", "publisher", "filter-arch", "arch", "scope", "exclude",
+		"modified-since", "min-size", "sort", "limit", "format", "output-format", "template",
+		"template-file", "timing", "strict", "strict-unnamed", "show-errors", "cross-check",
+		"changed-since-cache", "path-conflicts", "arch-mismatch", "group-by", "age", "no-dedup",
+		"label", "wrap",
+	} {
+		scanCmd.MarkFlagsMutuallyExclusive("stream", flag)
+	}
 }
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// writeTestFile writes content to a file in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(filename, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return filename
+}
+
+func TestLoadScanFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		hostname string
+		programs []string
+		wantErr  string
+	}{
+		{
+			name:     "bare array",
+			content:  `[{"Name": "Git"}, {"Name": "7-Zip"}]`,
+			programs: []string{"Git", "7-Zip"},
+		},
+		{
+			name:     "wrapped",
+			content:  `{"schemaVersion": 1, "hostname": "PC1", "programs": [{"Name": "Git"}]}`,
+			hostname: "PC1",
+			programs: []string{"Git"},
+		},
+		{
+			name: "appended scans use the last one",
+			content: `[
+				{"schemaVersion": 2, "hostname": "PC1", "programs": [{"Name": "Git"}]},
+				{"schemaVersion": 2, "hostname": "PC1", "programs": [{"Name": "Git"}, {"Name": "Python 3.12"}]}
+			]`,
+			hostname: "PC1",
+			programs: []string{"Git", "Python 3.12"},
+		},
+		{
+			name:     "empty array",
+			content:  `[]`,
+			programs: nil,
+		},
+		{
+			name:    "appended scan from a newer schema",
+			content: `[{"schemaVersion": 99, "programs": []}]`,
+			wantErr: "schema version 99",
+		},
+		{
+			name:    "wrapped without schemaVersion",
+			content: `{"programs": []}`,
+			wantErr: "no schemaVersion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := loadScanFile(writeTestFile(t, "scan.json", tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Hostname != tt.hostname {
+				t.Errorf("hostname = %q, want %q", result.Hostname, tt.hostname)
+			}
+			var names []string
+			for _, program := range result.Programs {
+				names = append(names, program.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.programs, ",") {
+				t.Errorf("programs = %v, want %v", names, tt.programs)
+			}
+		})
+	}
+}
+
+func TestLoadScanFileAfterAppend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.json")
+	opts := outputOptions{Append: true}
+
+	err := appendResults([]Program{{Name: "Git"}}, filename, "json", opts)
+	if err != nil {
+		t.Fatalf("first append: %v", err)
+	}
+	err = appendResults([]Program{{Name: "Git"}, {Name: "Node.js"}}, filename, "json", opts)
+	if err != nil {
+		t.Fatalf("second append: %v", err)
+	}
+
+	result, err := loadScanFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SchemaVersion != schemaVersion || len(result.Programs) != 2 || result.Programs[1].Name != "Node.js" {
+		t.Errorf("got schema %d with %+v, want the second scan", result.SchemaVersion, result.Programs)
+	}
+}
+
+func TestUpdateBaselineKeepsAppendedScans(t *testing.T) {
+	dir := t.TempDir()
+
+	// A baseline collected with --output-append gets the new scan at the end
+	history := filepath.Join(dir, "history.json")
+	for _, programs := range [][]Program{{{Name: "Git"}}, {{Name: "Git"}, {Name: "Node.js"}}} {
+		err := appendResults(programs, history, "json", outputOptions{Append: true})
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	err := updateBaseline([]Program{{Name: "Git"}, {Name: "Node.js"}, {Name: "Python 3.12"}}, history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var scans []json.RawMessage
+	err = json.Unmarshal(data, &scans)
+	if err != nil || len(scans) != 3 {
+		t.Fatalf("history holds %d scans (%v), want all 3 kept", len(scans), err)
+	}
+	result, err := loadScanFile(history)
+	if err != nil || len(result.Programs) != 3 {
+		t.Errorf("last scan has %d programs (%v), want the update's 3", len(result.Programs), err)
+	}
+
+	// A single-scan baseline is simply replaced
+	single := filepath.Join(dir, "baseline.json")
+	err = saveToJSON([]Program{{Name: "Git"}}, single, outputOptions{Wrap: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = updateBaseline([]Program{{Name: "Node.js"}}, single)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err = os.ReadFile(single)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isAppendedScan(data) {
+		t.Error("a single baseline became an appended file")
+	}
+	result, err = loadScanFile(single)
+	if err != nil || len(result.Programs) != 1 || result.Programs[0].Name != "Node.js" {
+		t.Errorf("got %+v (%v), want only the update's scan", result.Programs, err)
+	}
+}
+
+func TestIsAppendedScan(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"appended scans", `[{"schemaVersion": 2, "programs": []}, {"schemaVersion": 2, "programs": []}]`, true},
+		{"one appended scan", `[{"schemaVersion": 2, "programs": []}]`, true},
+		{"wrapped scan", `{"schemaVersion": 2, "programs": []}`, false},
+		{"bare array", `[{"Name": "Git"}]`, false},
+		{"empty array", `[]`, false},
+		{"empty file", ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAppendedScan([]byte(tt.data)); got != tt.want {
+				t.Errorf("isAppendedScan(%s) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateOutputPendingFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "programs.json")
+
+	committed, err := createOutput(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	discarded, err := createOutput(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pending := func() int {
+		pendingOutputs.Lock()
+		defer pendingOutputs.Unlock()
+		return len(pendingOutputs.names)
+	}
+	if pending() != 2 {
+		t.Fatalf("pending files = %d, want 2", pending())
+	}
+
+	committed.Write([]byte("[]"))
+	err = committed.Commit()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	discarded.Close()
+
+	if pending() != 0 {
+		t.Errorf("pending files = %d after Commit and Close, want 0", pending())
+	}
+	if pendingOutputs.interrupt != nil {
+		t.Error("Ctrl+C is still caught with no temporary files left")
+	}
+
+	// Only the committed file is left: both temporary files are gone
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "programs.json" {
+		t.Errorf("directory holds %v, want only programs.json", entries)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"Git", 10, "Git"},
+		{"Git", 3, "Git"},
+		{"Microsoft Visual C++", 10, "Microso..."},
+		{"Microsoft Visual C++", 0, "Microsoft Visual C++"},
+		{"Microsoft Visual C++", -1, "Microsoft Visual C++"},
+		{"Microsoft", 3, "Mic"}, // Too short for "..."
+		{"Name\twith\ttabs", 0, "Name with tabs"},
+		{"Zwei\nZeilen", 20, "Zwei Zeilen"},
+		{"Überprüfung für Größen", 10, "Überprü..."}, // Cut by characters, not bytes
+		{"", 5, ""},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.text, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	longName := "Microsoft Visual C++ 2015-2022 Redistributable (x64) - 14.38.33135 with a very long name"
+	programs := []Program{
+		{Name: "Git", Version: "2.43.0", Publisher: "The Git Development Community"},
+		{Name: longName, Version: "14.38.33135.0", Publisher: "Microsoft Corporation"},
+	}
+
+	t.Run("width 80", func(t *testing.T) {
+		var output strings.Builder
+		err := writeTable(&output, programs, 80)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimRight(output.String(), "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("got %d lines, want 4 (header, rule and two programs):\n%s", len(lines), output.String())
+		}
+		for _, line := range lines {
+			if utf8.RuneCountInString(strings.TrimRight(line, " ")) >= 80 {
+				t.Errorf("line is %d characters, want less than 80: %q", utf8.RuneCountInString(line), line)
+			}
+		}
+		if strings.Contains(output.String(), longName) || !strings.Contains(lines[3], "...") {
+			t.Errorf("long name wasn't cut with \"...\":\n%s", output.String())
+		}
+		if !strings.HasPrefix(lines[2], "Git ") {
+			t.Errorf("short name was changed: %q", lines[2])
+		}
+	})
+
+	t.Run("width 0", func(t *testing.T) {
+		var output strings.Builder
+		err := writeTable(&output, programs, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{longName, "The Git Development Community", "14.38.33135.0"} {
+			if !strings.Contains(output.String(), want) {
+				t.Errorf("output is missing %q:\n%s", want, output.String())
+			}
+		}
+		if strings.Contains(output.String(), "...") {
+			t.Errorf("nothing should be cut without a width:\n%s", output.String())
+		}
+	})
+}
@@ -0,0 +1,593 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryKey is the part of registry.Key the scanner uses
+// Tests can implement it with a fake that serves predefined values
+type registryKey interface {
+	ReadSubKeyNames(n int) ([]string, error)
+	GetStringValue(name string) (string, uint32, error)
+	GetIntegerValue(name string) (uint64, uint32, error)
+	ModTime() (time.Time, error) // When the key was last written
+	Close() error
+}
+
+// registryBackend opens registry keys
+// The real implementation is windowsRegistry; tests inject a fake
+type registryBackend interface {
+	OpenKey(root registry.Key, path string, access uint32) (registryKey, error)
+}
+
+// windowsRegistry is the registryBackend that reads the real Windows registry
+type windowsRegistry struct{}
+
+// OpenKey opens a key with registry.OpenKey
+func (windowsRegistry) OpenKey(root registry.Key, path string, access uint32) (registryKey, error) {
+	key, err := registry.OpenKey(root, path, access)
+	if err != nil {
+		return nil, err
+	}
+	return windowsKey{key}, nil
+}
+
+// windowsKey wraps a registry.Key to add ModTime
+type windowsKey struct {
+	registry.Key
+}
+
+// ModTime returns the key's last-write time from Stat
+func (k windowsKey) ModTime() (time.Time, error) {
+	info, err := k.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// scanLocation is one registry location the scanner reads
+type scanLocation struct {
+	Root        registry.Key
+	Path        string
+	View        uint32 // registry.WOW64_64KEY or WOW64_32KEY to pick a registry view, 0 when it isn't split
+	Arch        string // "x64", "x86", or "" when the location isn't split by architecture
+	Source      string // Stored on every program found here, e.g. "HKLM 64-bit"
+	Description string // Used in progress messages, e.g. "64-bit programs"
+}
+
+// defaultLocations are the three places Control Panel reads installed programs from
+// on 64-bit Windows; locationsForOS trims them for 32-bit Windows
+func defaultLocations() []scanLocation {
+	return []scanLocation{
+		{
+			Root:        registry.LOCAL_MACHINE,
+			Path:        `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`,
+			View:        registry.WOW64_64KEY,
+			Arch:        "x64",
+			Source:      sourceHKLM64,
+			Description: "64-bit programs",
+		},
+		{
+			// WOW64 = Windows on Windows 64-bit
+			// The 32-bit view is opened with WOW64_32KEY rather than through the
+			// WOW6432Node path, so Windows applies its own redirection rules
+			Root:        registry.LOCAL_MACHINE,
+			Path:        `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`,
+			View:        registry.WOW64_32KEY,
+			Arch:        "x86",
+			Source:      sourceHKLM32,
+			Description: "32-bit programs",
+		},
+		{
+			// Per-user programs ("install just for me"), e.g. many Chrome and VS Code installs
+			// HKCU isn't split into 64-bit and 32-bit views, so the architecture is unknown
+			Root:        registry.CURRENT_USER,
+			Path:        userUninstallKey,
+			Arch:        "",
+			Source:      sourceHKCU,
+			Description: "per-user programs",
+		},
+	}
+}
+
+// locationsForOS drops the 64-bit view on 32-bit Windows
+// The registry isn't split there, so both HKLM locations would read the same
+// key and list every program twice; the 32-bit one is kept, as its programs are x86
+func locationsForOS(locations []scanLocation, is64Bit bool) []scanLocation {
+	if is64Bit {
+		return locations
+	}
+	var kept []scanLocation
+	for _, loc := range locations {
+		if loc.View != registry.WOW64_64KEY {
+			kept = append(kept, loc)
+		}
+	}
+	return kept
+}
+
+// is64BitWindows reports whether Windows itself is 64-bit
+// A 64-bit build can only run there; a 32-bit build is on 64-bit Windows when it runs under WOW64
+func is64BitWindows() bool {
+	if runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64" {
+		return true
+	}
+	var wow64 bool
+	err := windows.IsWow64Process(windows.CurrentProcess(), &wow64)
+	return err == nil && wow64
+}
+
+// Values accepted by --registry-view
+const (
+	registryViewBoth = "both" // Both views, the default
+	registryView32   = "32"   // Only the 32-bit view
+	registryView64   = "64"   // Only the 64-bit view
+)
+
+// locationsForView keeps the locations in one registry view ("32" or "64"),
+// or all of them for "both"
+// Per-user locations aren't split into views, so they're always kept
+func locationsForView(locations []scanLocation, view string) ([]scanLocation, error) {
+	var want uint32
+	switch view {
+	case registryViewBoth:
+		return locations, nil
+	case registryView32:
+		want = registry.WOW64_32KEY
+	case registryView64:
+		want = registry.WOW64_64KEY
+	default:
+		return nil, fmt.Errorf("unknown registry view %q (valid values: both, 32, 64)", view)
+	}
+
+	var kept []scanLocation
+	for _, loc := range locations {
+		if loc.View == 0 || loc.View == want {
+			kept = append(kept, loc)
+		}
+	}
+	return kept, nil
+}
+
+// fullPath names a location in progress messages and error reports,
+// e.g. `HKLM\SOFTWARE\...\Uninstall (32-bit view)`
+func (loc scanLocation) fullPath() string {
+	path := rootName(loc.Root) + `\` + loc.Path
+	switch loc.View {
+	case registry.WOW64_32KEY:
+		path += " (32-bit view)"
+	case registry.WOW64_64KEY:
+		path += " (64-bit view)"
+	}
+	return path
+}
+
+// Scanner reads installed programs from the registry
+// Everything it needs is a field, so tests can swap the registry for a fake
+// and point it at their own locations
+type Scanner struct {
+	Registry     registryBackend // Where keys are read from
+	Locations    []scanLocation  // Registry locations to scan, in order
+	Workers      int             // How many subkeys are read at the same time
+	IncludeStore bool            // Also list Microsoft Store (AppX) packages
+	Progress     io.Writer       // Where step-by-step progress goes (io.Discard to hide it)
+	OnProgram    func(Program)   // Called with each program as soon as it's read (nil for none), for --stream
+	Timing       scanTiming      // How long each phase of the last scan took
+}
+
+// scanTiming is how long each phase of a scan took, shown by --timing
+// The phases add up across all locations
+type scanTiming struct {
+	OpenKeys    time.Duration // Opening the Uninstall keys
+	SubkeyNames time.Duration // Listing their subkeys
+	ReadValues  time.Duration // Reading every program's values (all workers, wall time)
+	Store       time.Duration // Listing Microsoft Store packages, if enabled
+	Total       time.Duration // The whole scan, start to finish
+}
+
+// newScanner returns a Scanner for the real registry and the default locations
+// Progress is hidden; set Progress to show it
+func newScanner(workers int) *Scanner {
+	return &Scanner{
+		Registry:  windowsRegistry{},
+		Locations: locationsForOS(defaultLocations(), is64BitWindows()),
+		Workers:   workers,
+		Progress:  io.Discard,
+	}
+}
+
+// scanVisiblePrograms scans and returns the programs the way the list shows them
+// (see visiblePrograms), for the commands that work on that list
+func scanVisiblePrograms(scanner *Scanner, includeUpdates bool) ([]Program, error) {
+	programs, _, err := scanner.scanAllPrograms()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan programs: %v", err)
+	}
+	return visiblePrograms(programs, includeUpdates), nil
+}
+
+// scanAllPrograms scans every configured location (and the Store if enabled)
+// This is the main function that coordinates the entire scanning process
+// Entries that couldn't be read are returned separately so callers can report them
+func (s *Scanner) scanAllPrograms() ([]Program, []skippedEntry, error) {
+	var allPrograms []Program
+	var allSkipped []skippedEntry
+	s.Timing = scanTiming{}
+	start := time.Now()
+
+	// Step 1: Scan each registry location in turn
+	for i, loc := range s.Locations {
+		if i > 0 {
+			fmt.Fprintln(s.Progress)
+		}
+		fmt.Fprintf(s.Progress, "Step %d: Scanning %s...\n", i+1, loc.Description)
+		fmt.Fprintf(s.Progress, "Location: %s\n", loc.fullPath())
+
+		programs, skipped, err := s.scanRegistryLocation(loc)
+		allSkipped = append(allSkipped, skipped...)
+		if err != nil {
+			hint := ""
+			if isAccessDenied(err) {
+				hint = " (try running as Administrator)"
+			}
+			fmt.Fprintf(os.Stderr, "Warning: Could not scan %s: %v%s\n", loc.Description, err, hint)
+			allSkipped = append(allSkipped, skippedEntry{Location: loc.fullPath(), Reason: err.Error(), AccessDenied: isAccessDenied(err)})
+		} else {
+			fmt.Fprintf(s.Progress, "Found %d %s\n", len(programs), loc.Description)
+			allPrograms = append(allPrograms, programs...)
+		}
+	}
+
+	// Step 2: Scan Microsoft Store (AppX) packages if requested
+	// Store apps don't use the Uninstall keys, so they're only found this way
+	if s.IncludeStore {
+		fmt.Fprintf(s.Progress, "\nStep %d: Scanning Microsoft Store packages...\n", len(s.Locations)+1)
+
+		storeStart := time.Now()
+		programsStore, err := scanAppXPackages()
+		s.Timing.Store = time.Since(storeStart)
+		if err != nil {
+			// Not fatal: the registry results are still worth having
+			fmt.Fprintf(os.Stderr, "Warning: Could not scan Microsoft Store packages: %v\n", err)
+		} else {
+			fmt.Fprintf(s.Progress, "Found %d Store packages\n", len(programsStore))
+			if s.OnProgram != nil {
+				for _, program := range programsStore {
+					s.OnProgram(program)
+				}
+			}
+			allPrograms = append(allPrograms, programsStore...)
+		}
+	}
+
+	s.Timing.Total = time.Since(start)
+	return allPrograms, allSkipped, nil
+}
+
+// lastChange returns the latest last-write time of the scanned locations
+// Adding or removing a program's subkey updates its location's time, so this
+// tells whether a cached scan is still current
+func (s *Scanner) lastChange() (time.Time, error) {
+	var latest time.Time
+	for _, loc := range s.Locations {
+		key, err := s.Registry.OpenKey(loc.Root, loc.Path, registry.QUERY_VALUE|loc.View)
+		if errors.Is(err, registry.ErrNotExist) {
+			continue // e.g. no per-user installs yet
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		modTime, err := key.ModTime()
+		key.Close()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if modTime.After(latest) {
+			latest = modTime
+		}
+	}
+	return latest, nil
+}
+
+// scanRegistryLocation opens a registry key and scans all its subkeys
+// Each subkey represents one installed program
+// Subkeys that can't be read are returned as skipped entries instead of failing the scan
+// Every program found is stamped with the location's architecture and source so we know where it came from
+func (s *Scanner) scanRegistryLocation(loc scanLocation) ([]Program, []skippedEntry, error) {
+	var programs []Program
+	var skipped []skippedEntry
+	location := loc.fullPath() // Used when reporting skipped entries
+
+	// Step 1: Open the registry key
+	// registry.OpenKey() is much simpler than raw Windows API calls!
+	// It handles all the UTF-16 conversion and error handling for us
+	fmt.Fprintf(s.Progress, "  Opening registry key: %s\n", loc.Path)
+	start := time.Now()
+	key, err := s.Registry.OpenKey(loc.Root, loc.Path, registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE|loc.View)
+	s.Timing.OpenKeys += time.Since(start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer key.Close() // Always close the key when done
+
+	// Step 2: Get all subkey names
+	// registry.ReadSubKeyNames() does all the enumeration work for us
+	fmt.Fprintf(s.Progress, "  Reading subkey names...\n")
+	start = time.Now()
+	subkeyNames, err := key.ReadSubKeyNames(-1) // -1 means read all subkeys
+	s.Timing.SubkeyNames += time.Since(start)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read subkey names: %w", err)
+	}
+
+	fmt.Fprintf(s.Progress, "  Found %d subkeys to process\n", len(subkeyNames))
+
+	// Step 3: Process the subkeys (each subkey = one program) with a pool of workers
+	// Registry handles aren't safe to share between goroutines, so each worker
+	// opens its own handle from the full key path instead of using key
+	// jobs is buffered so every subkey can be queued without waiting on the workers
+	// Each worker opens and reads its own subkeys, so this phase is timed as a whole
+	start = time.Now()
+	jobs := make(chan int, len(subkeyNames))
+	results := make(chan subkeyResult)
+	var wg sync.WaitGroup
+	workers := s.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				program, err := s.getProgramFromSubkey(loc.Root, loc.Path+`\`+subkeyNames[i], loc.View)
+				results <- subkeyResult{index: i, program: program, err: err}
+			}
+		}()
+	}
+
+	// Hand out the subkeys, then close results once every worker has finished
+	go func() {
+		for i := range subkeyNames {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Step 4: Collect the results as they come in
+	// Workers finish in any order, so results are put back in subkey order
+	// afterwards to keep the output the same from run to run
+	// The tracker turns the progress count into a rate and time-remaining estimate
+	var collected []subkeyResult
+	tracker := newProgressTracker(len(subkeyNames))
+	for result := range results {
+		// Label each program as it arrives, so OnProgram sees it complete
+		if result.err == nil && result.program.Name != "" {
+			result.program = loc.label(result.program)
+			if s.OnProgram != nil {
+				s.OnProgram(result.program)
+			}
+		}
+		collected = append(collected, result)
+
+		// Show progress every 50 programs
+		done := len(collected)
+		if done%50 == 0 && done < len(subkeyNames) {
+			tracker.update(done)
+			fmt.Fprintf(s.Progress, "  Processed %d/%d programs...%s\n", done, len(subkeyNames), tracker.status())
+		}
+	}
+	s.Timing.ReadValues += time.Since(start)
+	sort.Slice(collected, func(i, j int) bool {
+		return collected[i].index < collected[j].index
+	})
+
+	for _, result := range collected {
+		subkeyName := subkeyNames[result.index]
+		program, err := result.program, result.err
+		if err != nil {
+			// Skip programs that can't be read (some are system components)
+			// but remember why, so --strict can report it
+			skipped = append(skipped, skippedEntry{
+				Location:     location,
+				Subkey:       subkeyName,
+				Reason:       err.Error(),
+				MissingName:  errors.Is(err, errMissingName),
+				AccessDenied: isAccessDenied(err),
+			})
+			continue
+		}
+
+		// Only add programs that have a name (some entries are just metadata)
+		if program.Name != "" {
+			programs = append(programs, program)
+		} else {
+			skipped = append(skipped, skippedEntry{
+				Location:    location,
+				Subkey:      subkeyName,
+				Reason:      "DisplayName is empty",
+				MissingName: true,
+			})
+		}
+	}
+
+	return programs, skipped, nil
+}
+
+// label records where a program was found: its architecture, source and scope
+func (loc scanLocation) label(program Program) Program {
+	program.Architecture = loc.Arch
+	program.Source = loc.Source
+	program.Scope = scopeMachine
+	if loc.Root == registry.CURRENT_USER {
+		program.Scope = scopeUser
+	}
+	program.ArchMismatch = isArchMismatch(program)
+	return program
+}
+
+// subkeyResult is what a scan worker found in one subkey
+// index is the subkey's position in the list, used to restore the original order
+type subkeyResult struct {
+	index   int
+	program Program
+	err     error
+}
+
+// getProgramFromSubkey reads program details from a specific registry subkey
+// subkeyPath is the full path under root, so it can be called from any goroutine
+// view is the location's registry view (0 for none), so the subkey is read from the same view
+// This function extracts the DisplayName, DisplayVersion, InstallLocation, Publisher and more
+func (s *Scanner) getProgramFromSubkey(root registry.Key, subkeyPath string, view uint32) (Program, error) {
+	var program Program
+
+	// Step 1: Open the subkey
+	// This opens the specific program's registry entry
+	subkey, err := s.Registry.OpenKey(root, subkeyPath, registry.QUERY_VALUE|view)
+	if err != nil {
+		return program, fmt.Errorf("failed to open subkey: %w", err)
+	}
+	defer subkey.Close()
+
+	// Step 2: Read the DisplayName
+	// This is the name you see in "Add or Remove Programs"
+	name, _, err := subkey.GetStringValue("DisplayName")
+	if errors.Is(err, registry.ErrNotExist) {
+		// Some programs don't have a DisplayName, skip them
+		return program, errMissingName
+	}
+	if err != nil {
+		return program, fmt.Errorf("failed to read DisplayName: %w", err)
+	}
+	program.Name = strings.TrimSpace(name) // Remove extra whitespace
+
+	// Step 3: Read the DisplayVersion (optional)
+	// Not all programs have this, so we ignore errors
+	version, _, err := subkey.GetStringValue("DisplayVersion")
+	if err == nil {
+		program.Version = strings.TrimSpace(version)
+	}
+
+	// Step 4: Read the InstallLocation (optional)
+	// This is where the program is installed
+	path, _, err := subkey.GetStringValue("InstallLocation")
+	if err == nil {
+		program.Path = strings.TrimSpace(path)
+	}
+
+	// Step 5: Read the Publisher (optional)
+	// This is the company that made the software
+	publisher, _, err := subkey.GetStringValue("Publisher")
+	if err == nil {
+		program.Publisher = strings.TrimSpace(publisher)
+	}
+
+	// Step 6: Read the EstimatedSize (optional)
+	// This is a DWORD holding the installed size in kilobytes
+	size, _, err := subkey.GetIntegerValue("EstimatedSize")
+	if err == nil {
+		program.SizeKB = size
+	}
+
+	// Step 7: Read the InstallDate (optional)
+	// It's stored as a YYYYMMDD string, but not every installer gets it right
+	installDate, _, err := subkey.GetStringValue("InstallDate")
+	if err == nil {
+		program.InstallDate = parseInstallDate(installDate)
+		if program.InstallDate == nil {
+			// Keep values we couldn't parse so the information isn't lost
+			program.InstallDateRaw = strings.TrimSpace(installDate)
+		}
+	}
+
+	// Step 8: Read the key's last-write time
+	// This is a rough fallback for "when was it installed" when InstallDate is missing
+	modTime, err := subkey.ModTime()
+	if err == nil {
+		program.LastWriteTime = &modTime
+	}
+
+	// Step 9: Read the SystemComponent and WindowsInstaller flags and ParentKeyName (optional)
+	// Windows hides SystemComponent=1 entries from "Add or Remove Programs";
+	// WindowsInstaller=1 marks an MSI install, and ParentKeyName links an
+	// update or add-on to the program it belongs to
+	systemComponent, _, err := subkey.GetIntegerValue("SystemComponent")
+	if err == nil {
+		program.SystemComponent = systemComponent == 1
+	}
+	windowsInstaller, _, err := subkey.GetIntegerValue("WindowsInstaller")
+	if err == nil {
+		program.WindowsInstaller = windowsInstaller == 1
+	}
+	parentKeyName, _, err := subkey.GetStringValue("ParentKeyName")
+	if err == nil {
+		program.ParentKeyName = strings.TrimSpace(parentKeyName)
+	}
+
+	// Step 10: Read the uninstall, modify and repair commands (optional)
+	// QuietUninstallString is the silent variant; few installers provide it
+	program.UninstallString = getExpandedString(subkey, "UninstallString")
+	program.QuietUninstallString = getExpandedString(subkey, "QuietUninstallString")
+	program.ModifyPath = getExpandedString(subkey, "ModifyPath")
+	program.RepairString = getExpandedString(subkey, "RepairString")
+
+	// Step 11: Read the DisplayIcon (optional)
+	// It's usually an .exe or .ico path, often with an icon index like ",0"
+	program.Icon = parseIconPath(getExpandedString(subkey, "DisplayIcon"))
+
+	// Step 12: Read the homepage and support links (optional)
+	program.URLInfoAbout = getExpandedString(subkey, "URLInfoAbout")
+	program.HelpLink = getExpandedString(subkey, "HelpLink")
+
+	// Step 13: Record the MSI ProductCode and the bundle's UpgradeCode (optional)
+	// MSI installs name their subkey after the ProductCode GUID; deployment
+	// tools such as SCCM and Intune identify the software by these codes
+	subkeyName := subkeyPath[strings.LastIndex(subkeyPath, `\`)+1:]
+	if productCodePattern.MatchString(subkeyName) {
+		program.MSIProductCode = subkeyName
+	}
+	program.BundleUpgradeCode = getExpandedString(subkey, "BundleUpgradeCode")
+
+	// MSI packages rarely store a RepairString, but Windows Installer can always
+	// repair them by ProductCode ("/fomus" is msiexec's default repair mode)
+	if program.RepairString == "" && program.WindowsInstaller && program.MSIProductCode != "" {
+		program.RepairString = "MsiExec.exe /fomus " + program.MSIProductCode
+	}
+
+	return program, nil
+}
+
+// productCodePattern matches a GUID in braces, like {23170F69-40C1-2702-2301-000001000000}
+var productCodePattern = regexp.MustCompile(`^\{[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\}$`)
+
+// getExpandedString reads an optional string value and trims it
+// REG_EXPAND_SZ values like "%ProgramFiles%\App\uninstall.exe" are expanded,
+// so the command can be run as-is; missing or unreadable values return ""
+func getExpandedString(key registryKey, name string) string {
+	value, valueType, err := key.GetStringValue(name)
+	if err != nil {
+		return ""
+	}
+	if valueType == registry.EXPAND_SZ {
+		expanded, err := registry.ExpandString(value)
+		if err == nil {
+			value = expanded
+		}
+	}
+	return strings.TrimSpace(value)
+}
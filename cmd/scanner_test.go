@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// testUninstallKey is the location the fake registry serves in these tests
+const testUninstallKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+// testModTime is the last-write time every fake key reports
+var testModTime = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// fakeKey is one registry key in a fakeRegistry
+type fakeKey struct {
+	subkeys []string
+	values  map[string]string
+	dwords  map[string]uint64
+}
+
+func (k *fakeKey) ReadSubKeyNames(n int) ([]string, error) {
+	return k.subkeys, nil
+}
+
+func (k *fakeKey) GetStringValue(name string) (string, uint32, error) {
+	value, ok := k.values[name]
+	if !ok {
+		return "", 0, registry.ErrNotExist
+	}
+	return value, registry.SZ, nil
+}
+
+func (k *fakeKey) GetIntegerValue(name string) (uint64, uint32, error) {
+	value, ok := k.dwords[name]
+	if !ok {
+		return 0, 0, registry.ErrNotExist
+	}
+	return value, registry.DWORD, nil
+}
+
+func (k *fakeKey) ModTime() (time.Time, error) {
+	return testModTime, nil
+}
+
+func (k *fakeKey) Close() error {
+	return nil
+}
+
+// fakeRegistry serves predefined keys by path
+// Paths listed in failures can't be opened and return that error instead
+type fakeRegistry struct {
+	keys     map[string]*fakeKey
+	failures map[string]error
+}
+
+func (r *fakeRegistry) OpenKey(root registry.Key, path string, access uint32) (registryKey, error) {
+	if err, ok := r.failures[path]; ok {
+		return nil, err
+	}
+	key, ok := r.keys[path]
+	if !ok {
+		return nil, registry.ErrNotExist
+	}
+	return key, nil
+}
+
+// newFakeScanner returns a Scanner that reads one location from the fake registry
+// subkeys maps each subkey name under testUninstallKey to its values
+func newFakeScanner(subkeys map[string]*fakeKey, failures map[string]error) *Scanner {
+	reg := &fakeRegistry{
+		keys:     map[string]*fakeKey{testUninstallKey: {}},
+		failures: make(map[string]error),
+	}
+	parent := reg.keys[testUninstallKey]
+	for name, key := range subkeys {
+		parent.subkeys = append(parent.subkeys, name)
+		reg.keys[testUninstallKey+`\`+name] = key
+	}
+	for name, err := range failures {
+		parent.subkeys = append(parent.subkeys, name)
+		reg.failures[testUninstallKey+`\`+name] = err
+	}
+
+	scanner := newScanner(2)
+	scanner.Registry = reg
+	scanner.Locations = []scanLocation{{
+		Root:        registry.LOCAL_MACHINE,
+		Path:        testUninstallKey,
+		Arch:        "x64",
+		Source:      sourceHKLM64,
+		Description: "test programs",
+	}}
+	return scanner
+}
+
+func TestGetProgramFromSubkey(t *testing.T) {
+	installDate := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		key     *fakeKey
+		want    Program
+		wantErr error
+	}{
+		{
+			name: "all fields",
+			key: &fakeKey{
+				values: map[string]string{
+					"DisplayName":          "7-Zip 23.01 (x64)",
+					"DisplayVersion":       "23.01",
+					"InstallLocation":      `C:\Program Files\7-Zip\`,
+					"Publisher":            "Igor Pavlov",
+					"InstallDate":          "20240114",
+					"ParentKeyName":        "7-Zip",
+					"UninstallString":      `"C:\Program Files\7-Zip\Uninstall.exe"`,
+					"QuietUninstallString": `"C:\Program Files\7-Zip\Uninstall.exe" /S`,
+					"ModifyPath":           `"C:\Program Files\7-Zip\Setup.exe" /modify`,
+					"RepairString":         `"C:\Program Files\7-Zip\Setup.exe" /repair`,
+					"DisplayIcon":          `C:\Program Files\7-Zip\7zFM.exe,0`,
+					"URLInfoAbout":         "https://www.7-zip.org/",
+					"HelpLink":             " https://www.7-zip.org/support.html ",
+				},
+				dwords: map[string]uint64{
+					"EstimatedSize":    5800,
+					"SystemComponent":  1,
+					"WindowsInstaller": 1,
+				},
+			},
+			want: Program{
+				Name:                 "7-Zip 23.01 (x64)",
+				Version:              "23.01",
+				Path:                 `C:\Program Files\7-Zip\`,
+				Publisher:            "Igor Pavlov",
+				SizeKB:               5800,
+				InstallDate:          &installDate,
+				LastWriteTime:        &testModTime,
+				SystemComponent:      true,
+				WindowsInstaller:     true,
+				ParentKeyName:        "7-Zip",
+				UninstallString:      `"C:\Program Files\7-Zip\Uninstall.exe"`,
+				QuietUninstallString: `"C:\Program Files\7-Zip\Uninstall.exe" /S`,
+				ModifyPath:           `"C:\Program Files\7-Zip\Setup.exe" /modify`,
+				RepairString:         `"C:\Program Files\7-Zip\Setup.exe" /repair`,
+				Icon:                 `C:\Program Files\7-Zip\7zFM.exe`,
+				URLInfoAbout:         "https://www.7-zip.org/",
+				HelpLink:             "https://www.7-zip.org/support.html",
+			},
+		},
+		{
+			name: "only a name",
+			key: &fakeKey{
+				values: map[string]string{"DisplayName": "Notepad++"},
+			},
+			want: Program{
+				Name:          "Notepad++",
+				LastWriteTime: &testModTime,
+			},
+		},
+		{
+			name: "whitespace is trimmed",
+			key: &fakeKey{
+				values: map[string]string{
+					"DisplayName":     "  Git  ",
+					"DisplayVersion":  " 2.43.0\t",
+					"InstallLocation": "  C:\\Program Files\\Git\n",
+					"Publisher":       " The Git Development Community ",
+					"InstallDate":     " 2024-01-14x ",
+				},
+			},
+			want: Program{
+				Name:           "Git",
+				Version:        "2.43.0",
+				Path:           `C:\Program Files\Git`,
+				Publisher:      "The Git Development Community",
+				InstallDateRaw: "2024-01-14x",
+				LastWriteTime:  &testModTime,
+			},
+		},
+		{
+			name: "missing DisplayName",
+			key: &fakeKey{
+				values: map[string]string{"DisplayVersion": "1.0"},
+			},
+			wantErr: errMissingName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := newFakeScanner(map[string]*fakeKey{"App": tt.key}, nil)
+
+			got, err := scanner.getProgramFromSubkey(registry.LOCAL_MACHINE, testUninstallKey+`\App`, 0)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("program =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProgramFromSubkeyMSICodes(t *testing.T) {
+	const productCode = "{23170F69-40C1-2702-2301-000001000000}"
+	scanner := newFakeScanner(map[string]*fakeKey{
+		productCode: {values: map[string]string{
+			"DisplayName":       "7-Zip 23.01 (x64 edition)",
+			"BundleUpgradeCode": "{B7D2D5A6-3D6E-4C1B-9A5E-0F3F6C0A1E2D}",
+		}, dwords: map[string]uint64{"WindowsInstaller": 1}},
+		"Git_is1": {values: map[string]string{"DisplayName": "Git"}},
+	}, nil)
+
+	msi, err := scanner.getProgramFromSubkey(registry.LOCAL_MACHINE, testUninstallKey+`\`+productCode, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msi.MSIProductCode != productCode {
+		t.Errorf("MSIProductCode = %q, want %q", msi.MSIProductCode, productCode)
+	}
+	if msi.BundleUpgradeCode != "{B7D2D5A6-3D6E-4C1B-9A5E-0F3F6C0A1E2D}" {
+		t.Errorf("BundleUpgradeCode = %q", msi.BundleUpgradeCode)
+	}
+	if want := "MsiExec.exe /fomus " + productCode; msi.RepairString != want {
+		t.Errorf("RepairString = %q, want %q", msi.RepairString, want)
+	}
+
+	// Subkeys that aren't GUIDs belong to other installers and have no ProductCode
+	git, err := scanner.getProgramFromSubkey(registry.LOCAL_MACHINE, testUninstallKey+`\Git_is1`, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if git.MSIProductCode != "" || git.RepairString != "" {
+		t.Errorf("MSIProductCode = %q, RepairString = %q, want both empty", git.MSIProductCode, git.RepairString)
+	}
+}
+
+func TestGetProgramFromSubkeyOpenFailure(t *testing.T) {
+	scanner := newFakeScanner(nil, map[string]error{"Locked": windows.ERROR_ACCESS_DENIED})
+
+	_, err := scanner.getProgramFromSubkey(registry.LOCAL_MACHINE, testUninstallKey+`\Locked`, 0)
+	if !errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		t.Fatalf("error = %v, want ERROR_ACCESS_DENIED", err)
+	}
+}
+
+func TestScanRegistryLocation(t *testing.T) {
+	scanner := newFakeScanner(
+		map[string]*fakeKey{
+			"Git_is1": {values: map[string]string{"DisplayName": "Git", "DisplayVersion": "2.43.0"}},
+			"NoName":  {values: map[string]string{"DisplayVersion": "1.0"}},
+			"Blank":   {values: map[string]string{"DisplayName": "   "}},
+			"7-Zip":   {values: map[string]string{"DisplayName": "7-Zip 23.01 (x64)"}},
+			"Tool":    {values: map[string]string{"DisplayName": "Tool", "InstallLocation": `C:\Program Files (x86)\Tool`}},
+		},
+		map[string]error{
+			"Locked": windows.ERROR_ACCESS_DENIED,
+			"Gone":   registry.ErrNotExist,
+		},
+	)
+
+	programs, skipped, err := scanner.scanRegistryLocation(scanner.Locations[0])
+	if err != nil {
+		t.Fatalf("unreadable entries should not abort the scan: %v", err)
+	}
+
+	// Step 1: The readable programs are all there, stamped with the location's details
+	var names []string
+	for _, program := range programs {
+		names = append(names, program.Name)
+		if program.Architecture != "x64" || program.Source != sourceHKLM64 || program.Scope != scopeMachine {
+			t.Errorf("%s: architecture/source/scope = %q/%q/%q, want x64/%q/%q",
+				program.Name, program.Architecture, program.Source, program.Scope, sourceHKLM64, scopeMachine)
+		}
+		if program.Name == "Tool" && !program.ArchMismatch {
+			t.Errorf("Tool: a 64-bit entry in Program Files (x86) should be flagged ArchMismatch")
+		}
+	}
+	sort.Strings(names) // Subkeys come from a map, so their order isn't fixed
+	got := strings.Join(names, ", ")
+	want := "7-Zip 23.01 (x64), Git, Tool"
+	if got != want {
+		t.Errorf("programs = %s, want %s", got, want)
+	}
+
+	// Step 2: Every other entry is reported as skipped, with the right reason
+	wantSkipped := map[string]skippedEntry{
+		"NoName": {MissingName: true},
+		"Blank":  {MissingName: true},
+		"Locked": {AccessDenied: true},
+		"Gone":   {},
+	}
+	if len(skipped) != len(wantSkipped) {
+		t.Fatalf("skipped %d entries, want %d: %+v", len(skipped), len(wantSkipped), skipped)
+	}
+	for _, entry := range skipped {
+		want, ok := wantSkipped[entry.Subkey]
+		if !ok {
+			t.Errorf("unexpected skipped entry %q", entry.Subkey)
+			continue
+		}
+		if entry.MissingName != want.MissingName || entry.AccessDenied != want.AccessDenied {
+			t.Errorf("%s: MissingName/AccessDenied = %v/%v, want %v/%v",
+				entry.Subkey, entry.MissingName, entry.AccessDenied, want.MissingName, want.AccessDenied)
+		}
+		if entry.Location != `HKLM\`+testUninstallKey {
+			t.Errorf("%s: location = %q", entry.Subkey, entry.Location)
+		}
+	}
+}
+
+func TestScanRegistryLocationOpenFailure(t *testing.T) {
+	scanner := newFakeScanner(nil, nil)
+	scanner.Registry.(*fakeRegistry).failures[testUninstallKey] = windows.ERROR_ACCESS_DENIED
+
+	_, _, err := scanner.scanRegistryLocation(scanner.Locations[0])
+	if !errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		t.Fatalf("error = %v, want ERROR_ACCESS_DENIED", err)
+	}
+}
+
+func TestScanAllProgramsUserScope(t *testing.T) {
+	scanner := newFakeScanner(map[string]*fakeKey{
+		"Code": {values: map[string]string{"DisplayName": "Microsoft Visual Studio Code (User)"}},
+	}, nil)
+	scanner.Locations[0].Root = registry.CURRENT_USER
+	scanner.Locations[0].Arch = ""
+	scanner.Locations[0].Source = sourceHKCU
+
+	programs, skipped, err := scanner.scanAllPrograms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(programs) != 1 || len(skipped) != 0 {
+		t.Fatalf("got %d programs and %d skipped, want 1 and 0", len(programs), len(skipped))
+	}
+	if programs[0].Scope != scopeUser || programs[0].Source != sourceHKCU {
+		t.Errorf("scope/source = %q/%q, want %q/%q", programs[0].Scope, programs[0].Source, scopeUser, sourceHKCU)
+	}
+}
+
+func TestLocationsForView(t *testing.T) {
+	tests := []struct {
+		view    string
+		sources []string
+	}{
+		{registryViewBoth, []string{sourceHKLM64, sourceHKLM32, sourceHKCU}},
+		{registryView64, []string{sourceHKLM64, sourceHKCU}},
+		{registryView32, []string{sourceHKLM32, sourceHKCU}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.view, func(t *testing.T) {
+			locations, err := locationsForView(defaultLocations(), tt.view)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var sources []string
+			for _, loc := range locations {
+				sources = append(sources, loc.Source)
+			}
+			if !reflect.DeepEqual(sources, tt.sources) {
+				t.Errorf("sources = %v, want %v", sources, tt.sources)
+			}
+		})
+	}
+
+	_, err := locationsForView(defaultLocations(), "wow64")
+	if err == nil {
+		t.Error("expected an error for an unknown view")
+	}
+}
+
+func TestLocationsForOS(t *testing.T) {
+	tests := []struct {
+		name    string
+		is64Bit bool
+		sources []string
+	}{
+		{"64-bit Windows", true, []string{sourceHKLM64, sourceHKLM32, sourceHKCU}},
+		{"32-bit Windows", false, []string{sourceHKLM32, sourceHKCU}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []string
+			for _, loc := range locationsForOS(defaultLocations(), tt.is64Bit) {
+				sources = append(sources, loc.Source)
+			}
+			if !reflect.DeepEqual(sources, tt.sources) {
+				t.Errorf("sources = %v, want %v", sources, tt.sources)
+			}
+		})
+	}
+}
+
+func TestScanAllProgramsOnProgram(t *testing.T) {
+	scanner := newFakeScanner(map[string]*fakeKey{
+		"Git_is1": {values: map[string]string{"DisplayName": "Git"}},
+		"7-Zip":   {values: map[string]string{"DisplayName": "7-Zip 23.01 (x64)"}},
+		"KB123":   {values: map[string]string{}},
+	}, nil)
+	var streamed []Program
+	scanner.OnProgram = func(program Program) {
+		streamed = append(streamed, program)
+	}
+
+	programs, _, err := scanner.scanAllPrograms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Slice(streamed, func(i, j int) bool { return streamed[i].Name < streamed[j].Name })
+	sort.Slice(programs, func(i, j int) bool { return programs[i].Name < programs[j].Name })
+	if !reflect.DeepEqual(streamed, programs) {
+		t.Errorf("streamed %+v, want the scanned programs %+v", streamed, programs)
+	}
+	if len(streamed) != 2 {
+		t.Fatalf("streamed %d programs, want 2", len(streamed))
+	}
+	if streamed[0].Source != sourceHKLM64 {
+		t.Errorf("source = %q, want %q", streamed[0].Source, sourceHKLM64)
+	}
+}
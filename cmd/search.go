@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <term>",
+	Short: "Find installed programs by name",
+	Long: `Scan the registry and show only the programs whose name contains
+the search term (case-insensitive).
+
+search only prints the matches, so it's quick to use from scripts.
+If exactly one program matches, its full details are shown. Results can be
+saved with -o just like the scan command.
+
+- --exact: Only matches programs whose whole name equals the term (case-insensitive)
+- --json: Prints the matches as JSON, for scripts. Like scan, the list is
+  wrapped in an object with schemaVersion and scan details; --wrap=false
+  prints a bare array instead (also for -o .json files)
+
+Examples:
+  winclone search python                 # Is Python installed?
+  winclone search "Git" --exact --json   # Exact lookup for a script
+  winclone search "visual c++" -o vc.csv # Save the matches as CSV`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone search <term>"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		term := args[0]
+		exact, _ := cmd.Flags().GetBool("exact")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		wrap, _ := cmd.Flags().GetBool("wrap")
+		opts := outputOptions{Wrap: wrap}
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		// Keep only the programs whose name matches
+		var matches []Program
+		if exact {
+			matches = filterByExactName(programs, term)
+		} else {
+			matches = filterByName(programs, term)
+		}
+		sortPrograms(matches, "name")
+
+		// JSON output always holds an array, even when nothing matched
+		if asJSON {
+			if matches == nil {
+				matches = []Program{}
+			}
+			err := writeJSON(os.Stdout, matches, opts)
+			if err != nil {
+				return fmt.Errorf("failed to encode JSON: %v", err)
+			}
+			return nil
+		}
+
+		// Save the matches if requested
+		outputFile, _ := cmd.Flags().GetString("output")
+		if outputFile != "" {
+			err := saveResults(matches, outputFile, opts)
+			return err
+		}
+
+		// One match gets the full details, several get a short list
+		if len(matches) == 0 {
+			fmt.Printf("No programs match %q\n", term)
+			return nil
+		}
+		if len(matches) == 1 {
+			displayProgram(1, matches[0], opts, time.Now())
+			return nil
+		}
+		// The count goes to stderr so scripts reading the list don't have to skip it
+		fmt.Fprintf(os.Stderr, "%d programs match %q\n", len(matches), term)
+		for i, program := range matches {
+			fmt.Printf("%d. %s\n", i+1, programLabel(program))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	// Add the --exact flag for whole-name matches
+	searchCmd.Flags().Bool("exact", false, "Match the whole program name instead of any part of it")
+
+	// Add the --json flag for scripts
+	searchCmd.Flags().Bool("json", false, "Print the matches as JSON")
+
+	// Add the --wrap flag, as for scan, so saved matches can be read back by diff and compare
+	searchCmd.Flags().Bool("wrap", true, "Wrap JSON output in an object with schemaVersion, winCloneVersion and scan details (--wrap=false for a bare array)")
+
+	searchCmd.Flags().StringP("output", "o", "", "Save matches to file (JSON: .json, NDJSON: .ndjson, CSV: .csv, HTML: .html, Markdown: .md, YAML: .yaml, XML: .xml, SQLite: .db, Text: .txt)")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(searchCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the program inventory over HTTP",
+	Long: `Start a small HTTP server so dashboards and monitoring agents can
+query this machine's installed programs without running WinClone themselves.
+
+Endpoints:
+  GET /programs              The full scan as JSON (the same envelope as scan -o file.json)
+  GET /programs?name=python  Only programs whose name contains "python" (case-insensitive)
+  GET /programs/count        The number of programs, as a plain integer
+
+Scans are cached like "winclone scan" does, so frequent requests don't read
+the registry every time (see --cache-ttl).
+
+The server listens on 127.0.0.1 by default, so only this machine can reach it.
+Listening on another address exposes the inventory to the network; there is
+no authentication.
+
+Requests must name the server as localhost, 127.0.0.1, [::1] or the --addr
+host in their Host header; others get 403 Forbidden. This stops web pages
+from reading the inventory through DNS rebinding. Slow clients are cut off
+after a timeout instead of holding connections open.
+
+Examples:
+  winclone serve                         # http://127.0.0.1:8080/programs
+  winclone serve --addr 127.0.0.1:9000   # Use another port`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone serve"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		addr, _ := cmd.Flags().GetString("addr")
+		cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+
+		server := &inventoryServer{cacheTTL: cacheTTL, includeUpdates: includeUpdates}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /programs", server.handlePrograms)
+		mux.HandleFunc("GET /programs/count", server.handleCount)
+
+		fmt.Fprintf(os.Stderr, "WinClone - Serving the program inventory on http://%s/programs\n", addr)
+		fmt.Fprintln(os.Stderr, "Press Ctrl+C to stop")
+
+		httpServer := &http.Server{
+			Addr:              addr,
+			Handler:           checkHost(allowedHosts(addr), mux),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		}
+		err := httpServer.ListenAndServe()
+		if err != nil {
+			return fmt.Errorf("server stopped: %v", err)
+		}
+		return nil
+	},
+}
+
+// allowedHosts are the Host header values the server answers to: the loopback
+// names, plus the --addr host when it names a specific address
+func allowedHosts(addr string) map[string]bool {
+	hosts := map[string]bool{"localhost": true, "127.0.0.1": true, "::1": true}
+	host, _, err := net.SplitHostPort(addr)
+	if err == nil && host != "" && host != "0.0.0.0" && host != "::" {
+		hosts[strings.ToLower(host)] = true
+	}
+	return hosts
+}
+
+// checkHost rejects requests whose Host header isn't one of hosts
+// A page on another site can point its own domain at 127.0.0.1 (DNS rebinding),
+// but the browser still sends that domain as the Host, so it's refused here
+func checkHost(hosts map[string]bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = strings.Trim(r.Host, "[]") // No port in the header
+		}
+		if !hosts[strings.ToLower(host)] {
+			http.Error(w, "unknown host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// inventoryServer answers the HTTP requests for "winclone serve"
+type inventoryServer struct {
+	mu             sync.Mutex // Only one scan runs at a time, even with many requests
+	cacheTTL       time.Duration
+	includeUpdates bool // Keep Windows update entries (--include-updates)
+}
+
+// programs returns the current inventory, from the cache if it's fresh enough
+// System components, Windows updates and duplicates are left out, as in the scan command's default view
+func (s *inventoryServer) programs() ([]Program, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scanner := newScanner(runtime.NumCPU())
+	programs, cached := loadCachedScan(scanner, s.cacheTTL)
+	if !cached {
+		var err error
+		programs, _, err = scanner.scanAllPrograms()
+		if err != nil {
+			return nil, err
+		}
+		err = saveScanCache(programs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not update the scan cache: %v\n", err)
+		}
+	}
+
+	programs = visiblePrograms(programs, s.includeUpdates)
+	sortPrograms(programs, "name")
+	return programs, nil
+}
+
+// handlePrograms serves GET /programs, optionally filtered with ?name=
+func (s *inventoryServer) handlePrograms(w http.ResponseWriter, r *http.Request) {
+	programs, err := s.programs()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to scan programs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if name := r.URL.Query().Get("name"); name != "" {
+		programs = filterByName(programs, name)
+	}
+	if programs == nil {
+		programs = []Program{} // "programs": [] rather than null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+	err = encoder.Encode(newScanResult(programs, ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not send the response: %v\n", err)
+	}
+}
+
+// handleCount serves GET /programs/count as a plain integer
+func (s *inventoryServer) handleCount(w http.ResponseWriter, r *http.Request) {
+	programs, err := s.programs()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to scan programs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, len(programs))
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	// Add the --addr flag; the default keeps the server local to this machine
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on (host:port)")
+
+	// Add the --cache-ttl flag so requests can share a recent scan
+	serveCmd.Flags().Duration("cache-ttl", 5*time.Minute, "Reuse a scan younger than this between requests (0 to scan every time)")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(serveCmd)
+}
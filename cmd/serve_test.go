@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckHost(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		addr string
+		host string
+		want int
+	}{
+		{"127.0.0.1:8080", "127.0.0.1:8080", http.StatusOK},
+		{"127.0.0.1:8080", "localhost:8080", http.StatusOK},
+		{"127.0.0.1:8080", "LOCALHOST", http.StatusOK},
+		{"127.0.0.1:8080", "[::1]:8080", http.StatusOK},
+		{"127.0.0.1:8080", "evil.example.com:8080", http.StatusForbidden},
+		{"127.0.0.1:8080", "evil.example.com", http.StatusForbidden},
+		{"192.168.1.20:8080", "192.168.1.20:8080", http.StatusOK},
+		{"inventory.corp:8080", "inventory.corp:8080", http.StatusOK},
+		{"0.0.0.0:8080", "0.0.0.0:8080", http.StatusForbidden},
+		{":8080", "evil.example.com:8080", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr+" "+tt.host, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/programs", nil)
+			request.Host = tt.host
+			recorder := httptest.NewRecorder()
+
+			checkHost(allowedHosts(tt.addr), ok).ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotTimeLayout is RFC3339 with the colons swapped for dashes,
+// because Windows doesn't allow ":" in file names
+const snapshotTimeLayout = "2006-01-02T15-04-05Z07-00"
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save a timestamped scan to the snapshot directory",
+	Long: `Scan the installed programs and save the result as a JSON snapshot.
+
+Snapshots are named after the time they were taken, e.g.
+2025-01-14T09-30-00Z.json, and are stored in %APPDATA%\winclone\snapshots
+unless --dir says otherwise. They use the wrapped JSON layout (with
+schemaVersion, hostname and timestamp), so "winclone diff" can compare any
+two of them.
+
+Examples:
+  winclone snapshot                          # Take a snapshot
+  winclone snapshot --label "before update"  # Take a labelled snapshot
+  winclone snapshot list                     # Show saved snapshots`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone snapshot"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		dir, _ := cmd.Flags().GetString("dir")
+		label, _ := cmd.Flags().GetString("label")
+
+		fmt.Fprintln(os.Stderr, "WinClone - Taking a snapshot...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+		sortPrograms(programs, "name")
+
+		// Create the snapshot directory the first time
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot directory: %v", err)
+		}
+
+		filename := filepath.Join(dir, time.Now().UTC().Format(snapshotTimeLayout)+".json")
+		err = saveToJSON(programs, filename, outputOptions{Wrap: true, Label: label})
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot: %v", err)
+		}
+
+		fmt.Printf("\nSaved snapshot of %d programs to: %s\n", len(programs), filename)
+
+		return nil
+	},
+}
+
+// snapshotListCmd represents the "snapshot list" command
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snapshots, oldest first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone snapshot list"
+		dir, _ := cmd.Flags().GetString("dir")
+
+		files, err := listSnapshots(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot directory: %v", err)
+		}
+		if len(files) == 0 {
+			fmt.Printf("No snapshots in %s\n", dir)
+			return nil
+		}
+
+		fmt.Printf("Snapshots in %s:\n\n", dir)
+		for _, file := range files {
+			// Show the details stored inside each snapshot, if it can be read
+			result, err := loadScanFile(filepath.Join(dir, file))
+			if err != nil {
+				fmt.Printf("  %s  (unreadable: %v)\n", file, err)
+				continue
+			}
+
+			line := fmt.Sprintf("  %s  %d programs", file, len(result.Programs))
+			if result.Label != "" {
+				line += fmt.Sprintf("  %q", result.Label)
+			}
+			fmt.Println(line)
+		}
+
+		return nil
+	},
+}
+
+// defaultSnapshotDir returns %APPDATA%\winclone\snapshots
+// It falls back to the current directory if APPDATA can't be found
+func defaultSnapshotDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "snapshots"
+	}
+	return filepath.Join(configDir, "winclone", "snapshots")
+}
+
+// listSnapshots returns the names of the snapshot files in dir, oldest first
+// The timestamp names sort in time order, so a plain string sort is enough
+// A missing directory just means no snapshots have been taken yet
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+
+	// Add the --dir flag to both commands so "snapshot list" looks in the same place
+	snapshotCmd.PersistentFlags().String("dir", defaultSnapshotDir(), "Directory where snapshots are stored")
+
+	// Add the --label flag to note why a snapshot was taken
+	snapshotCmd.Flags().String("label", "", "Free-text label stored in the snapshot (e.g. \"before update\")")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(snapshotCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sortKeys lists the valid --sort values, in the order shown in help and errors
+var sortKeys = []string{"name", "version", "date", "size", "publisher"}
+
+// validateSortKey checks a --sort value, so a typo fails before the (slow) scan
+func validateSortKey(key string) error {
+	for _, valid := range sortKeys {
+		if key == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sort key %q (valid keys: %s)", key, strings.Join(sortKeys, ", "))
+}
+
+// sortPrograms orders the program list in place by the given key
+// The sorts are stable, so programs that compare equal keep their relative order
+func sortPrograms(programs []Program, key string) error {
+	var less func(a, b Program) bool
+
+	switch key {
+	case "name":
+		less = func(a, b Program) bool {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+	case "version":
+		less = func(a, b Program) bool {
+			return compareVersions(a.Version, b.Version) < 0
+		}
+	case "date":
+		// Newest first; programs without a date go last
+		less = func(a, b Program) bool {
+			if a.InstallDate == nil || b.InstallDate == nil {
+				return a.InstallDate != nil && b.InstallDate == nil
+			}
+			return a.InstallDate.After(*b.InstallDate)
+		}
+	case "size":
+		// Biggest first, so the space hogs are at the top
+		less = func(a, b Program) bool {
+			return a.SizeKB > b.SizeKB
+		}
+	case "publisher":
+		// Alphabetical by publisher, then by name within each publisher
+		less = func(a, b Program) bool {
+			pa, pb := strings.ToLower(a.Publisher), strings.ToLower(b.Publisher)
+			if pa != pb {
+				return pa < pb
+			}
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+	default:
+		return validateSortKey(key)
+	}
+
+	sort.SliceStable(programs, func(i, j int) bool {
+		return less(programs[i], programs[j])
+	})
+	return nil
+}
+
+// compareVersions compares two version strings like "1.10.2" and "1.9"
+// Numeric parts are compared as numbers, so 1.10 sorts after 1.9
+// It returns -1, 0 or 1 like strings.Compare
+func compareVersions(a, b string) int {
+	partsA := splitVersion(a)
+	partsB := splitVersion(b)
+
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+
+		// Fall back to text comparison for parts like "beta"
+		if errA != nil || errB != nil {
+			if c := strings.Compare(partsA[i], partsB[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if numA != numB {
+			if numA < numB {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// All shared parts are equal, so the longer version is the newer one
+	switch {
+	case len(partsA) < len(partsB):
+		return -1
+	case len(partsA) > len(partsB):
+		return 1
+	}
+	return 0
+}
+
+// splitVersion breaks a version into parts at dots, dashes, underscores and spaces
+func splitVersion(version string) []string {
+	return strings.FieldsFunc(strings.ToLower(version), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == ' '
+	})
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go driver, so WinClone still builds without cgo
+)
+
+// sqliteSchema creates the programs table, one column per Program field
+// A program is identified by the machine, its name, publisher and registry location,
+// so scanning again updates its row (e.g. a new version) instead of adding a duplicate
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS programs (
+	hostname               TEXT NOT NULL,
+	name                   TEXT NOT NULL,
+	version                TEXT,
+	path                   TEXT,
+	publisher              TEXT NOT NULL,
+	size_kb                INTEGER,
+	architecture           TEXT,
+	arch_mismatch          INTEGER,
+	source                 TEXT NOT NULL,
+	scope                  TEXT,
+	install_date           TEXT,
+	install_date_raw       TEXT,
+	last_write_time        TEXT,
+	system_component       INTEGER,
+	windows_installer      INTEGER,
+	parent_key_name        TEXT,
+	uninstall_string       TEXT,
+	quiet_uninstall_string TEXT,
+	modify_path            TEXT,
+	repair_string          TEXT,
+	url_info_about         TEXT,
+	help_link              TEXT,
+	msi_product_code       TEXT,
+	bundle_upgrade_code    TEXT,
+	icon                   TEXT,
+	last_scanned           TEXT NOT NULL,
+	PRIMARY KEY (hostname, name, publisher, source)
+)`
+
+// sqliteAddedColumns are the columns added to the programs table after it was
+// first released. CREATE TABLE IF NOT EXISTS leaves an existing table alone, so
+// migrateSQLite adds these to databases written by an older WinClone
+var sqliteAddedColumns = []struct {
+	name       string
+	definition string
+}{
+	{"windows_installer", "INTEGER"},
+	{"msi_product_code", "TEXT"},
+	{"bundle_upgrade_code", "TEXT"},
+	{"modify_path", "TEXT"},
+	{"repair_string", "TEXT"},
+}
+
+// sqliteUpsert inserts a program, or updates it if this machine already has that row
+const sqliteUpsert = `INSERT INTO programs (
+	hostname, name, version, path, publisher, size_kb, architecture, arch_mismatch,
+	source, scope, install_date, install_date_raw, last_write_time, system_component,
+	windows_installer, parent_key_name, uninstall_string, quiet_uninstall_string,
+	modify_path, repair_string, url_info_about, help_link, msi_product_code,
+	bundle_upgrade_code, icon, last_scanned
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (hostname, name, publisher, source) DO UPDATE SET
+	version = excluded.version,
+	path = excluded.path,
+	size_kb = excluded.size_kb,
+	architecture = excluded.architecture,
+	arch_mismatch = excluded.arch_mismatch,
+	scope = excluded.scope,
+	install_date = excluded.install_date,
+	install_date_raw = excluded.install_date_raw,
+	last_write_time = excluded.last_write_time,
+	system_component = excluded.system_component,
+	windows_installer = excluded.windows_installer,
+	parent_key_name = excluded.parent_key_name,
+	uninstall_string = excluded.uninstall_string,
+	quiet_uninstall_string = excluded.quiet_uninstall_string,
+	modify_path = excluded.modify_path,
+	repair_string = excluded.repair_string,
+	url_info_about = excluded.url_info_about,
+	help_link = excluded.help_link,
+	msi_product_code = excluded.msi_product_code,
+	bundle_upgrade_code = excluded.bundle_upgrade_code,
+	icon = excluded.icon,
+	last_scanned = excluded.last_scanned`
+
+// saveToSQLite saves the program list to a SQLite database
+// The file and table are created if needed; re-running adds new programs and
+// updates the existing ones, so one database can collect scans from many machines
+func saveToSQLite(programs []Program, filename string) error {
+	if filename == stdoutName {
+		return fmt.Errorf("a SQLite database can't be written to stdout")
+	}
+
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		return fmt.Errorf("failed to create table: %v", err)
+	}
+	err = migrateSQLite(db)
+	if err != nil {
+		return err
+	}
+
+	// One transaction for all rows: much faster, and a failure leaves the database as it was
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback() // Does nothing once the transaction is committed
+
+	stmt, err := tx.Prepare(sqliteUpsert)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %v", err)
+	}
+	defer stmt.Close()
+
+	hostname, _ := os.Hostname() // Leave it empty if the name can't be read
+	scanned := time.Now().Format(time.RFC3339)
+	for _, program := range programs {
+		_, err = stmt.Exec(
+			hostname, program.Name, program.Version, program.Path, program.Publisher,
+			program.SizeKB, program.Architecture, program.ArchMismatch,
+			program.Source, program.Scope, sqliteTime(program.InstallDate),
+			program.InstallDateRaw, sqliteTime(program.LastWriteTime), program.SystemComponent,
+			program.WindowsInstaller, program.ParentKeyName, program.UninstallString, program.QuietUninstallString,
+			program.ModifyPath, program.RepairString,
+			program.URLInfoAbout, program.HelpLink, program.MSIProductCode, program.BundleUpgradeCode,
+			program.Icon, scanned,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save %s: %v", program.Name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit: %v", err)
+	}
+	return nil
+}
+
+// sqliteTime stores a time as RFC 3339 text, which SQLite's date functions understand
+// A missing time is stored as NULL
+func sqliteTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// migrateSQLite adds any of sqliteAddedColumns the programs table doesn't have yet
+// Existing rows get NULL in the new columns until they're scanned again
+func migrateSQLite(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(programs)")
+	if err != nil {
+		return fmt.Errorf("failed to read table columns: %v", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		err = rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey)
+		if err != nil {
+			return fmt.Errorf("failed to read table columns: %v", err)
+		}
+		existing[name] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read table columns: %v", err)
+	}
+
+	for _, column := range sqliteAddedColumns {
+		if existing[column.name] {
+			continue
+		}
+		_, err = db.Exec("ALTER TABLE programs ADD COLUMN " + column.name + " " + column.definition)
+		if err != nil {
+			return fmt.Errorf("failed to add column %s: %v", column.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// sqliteFirstSchema is the programs table as the first SQLite release created it
+const sqliteFirstSchema = `CREATE TABLE programs (
+	hostname               TEXT NOT NULL,
+	name                   TEXT NOT NULL,
+	version                TEXT,
+	path                   TEXT,
+	publisher              TEXT NOT NULL,
+	size_kb                INTEGER,
+	architecture           TEXT,
+	arch_mismatch          INTEGER,
+	source                 TEXT NOT NULL,
+	scope                  TEXT,
+	install_date           TEXT,
+	install_date_raw       TEXT,
+	last_write_time        TEXT,
+	system_component       INTEGER,
+	parent_key_name        TEXT,
+	uninstall_string       TEXT,
+	quiet_uninstall_string TEXT,
+	url_info_about         TEXT,
+	help_link              TEXT,
+	icon                   TEXT,
+	last_scanned           TEXT NOT NULL,
+	PRIMARY KEY (hostname, name, publisher, source)
+)`
+
+// openOldSQLite creates a database the way the first SQLite release did, with one row in it
+func openOldSQLite(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "inventory.db")
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(sqliteFirstSchema)
+	if err != nil {
+		t.Fatalf("failed to create old table: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO programs (hostname, name, publisher, source, last_scanned)
+		VALUES ('OLD-PC', 'Old Program', '', 'HKLM 64-bit', '2024-01-01T00:00:00Z')`)
+	if err != nil {
+		t.Fatalf("failed to insert old row: %v", err)
+	}
+	return db, filename
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db, _ := openOldSQLite(t)
+
+	// Running it twice must not try to add the columns again
+	for run := 1; run <= 2; run++ {
+		err := migrateSQLite(db)
+		if err != nil {
+			t.Fatalf("migrateSQLite run %d: %v", run, err)
+		}
+	}
+
+	for _, column := range sqliteAddedColumns {
+		var count int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('programs') WHERE name = ?`, column.name).Scan(&count)
+		if err != nil || count != 1 {
+			t.Errorf("column %s: count = %d (err %v), want 1", column.name, count, err)
+		}
+	}
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM programs`).Scan(&count)
+	if err != nil || count != 1 {
+		t.Errorf("row count = %d (err %v), want the old row kept", count, err)
+	}
+}
+
+func TestSaveToSQLiteUpgradesOldDatabase(t *testing.T) {
+	db, filename := openOldSQLite(t)
+
+	program := Program{
+		Name:              "7-Zip 23.01 (x64)",
+		Publisher:         "Igor Pavlov",
+		Source:            sourceHKLM64,
+		WindowsInstaller:  true,
+		MSIProductCode:    "{23170F69-40C1-2702-2301-000001000000}",
+		BundleUpgradeCode: "{5F2B4A1C-0000-0000-0000-000000000000}",
+		ModifyPath:        "MsiExec.exe /I{23170F69-40C1-2702-2301-000001000000}",
+		RepairString:      "MsiExec.exe /fomus {23170F69-40C1-2702-2301-000001000000}",
+	}
+	err := saveToSQLite([]Program{program}, filename)
+	if err != nil {
+		t.Fatalf("saveToSQLite on an old database: %v", err)
+	}
+
+	var got Program
+	err = db.QueryRow(`SELECT windows_installer, msi_product_code, bundle_upgrade_code, modify_path, repair_string
+		FROM programs WHERE name = ?`, program.Name).
+		Scan(&got.WindowsInstaller, &got.MSIProductCode, &got.BundleUpgradeCode, &got.ModifyPath, &got.RepairString)
+	if err != nil {
+		t.Fatalf("failed to read the saved row: %v", err)
+	}
+	if got.WindowsInstaller != program.WindowsInstaller || got.MSIProductCode != program.MSIProductCode ||
+		got.BundleUpgradeCode != program.BundleUpgradeCode || got.ModifyPath != program.ModifyPath ||
+		got.RepairString != program.RepairString {
+		t.Errorf("saved row = %+v, want the values of %+v", got, program)
+	}
+}
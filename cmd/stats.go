@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// statsTopCount is how many of the largest programs the stats report lists
+const statsTopCount = 10
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize disk use, publishers and install dates",
+	Long: `Scan the installed programs and print an overview instead of the list:
+- Total disk space used (the sum of each program's estimated size)
+- The 10 largest programs
+- How many programs each publisher has installed
+- The oldest and newest install dates
+
+Sizes and dates come from the registry, so programs that don't record them
+are left out of those parts of the report.
+
+Examples:
+  winclone stats          # Print the overview
+  winclone stats --json   # Print the overview as JSON for dashboards`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone stats"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		// Status lines go to stderr so they never mix with the JSON on stdout
+		fmt.Fprintln(os.Stderr, "WinClone - Summarizing installed programs...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		stats := computeStats(programs)
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+			err = encoder.Encode(stats)
+			if err != nil {
+				return fmt.Errorf("failed to encode JSON: %v", err)
+			}
+			return nil
+		}
+
+		displayStats(stats)
+
+		return nil
+	},
+}
+
+// publisherCount is how many programs one publisher has installed
+type publisherCount struct {
+	Publisher string `json:"publisher"`
+	Count     int    `json:"count"`
+}
+
+// inventoryStats is the aggregated view printed by "winclone stats"
+type inventoryStats struct {
+	TotalPrograms int              `json:"totalPrograms"`
+	TotalSizeKB   uint64           `json:"totalSizeKB"`
+	Largest       []Program        `json:"largest"`
+	Publishers    []publisherCount `json:"publishers"`
+	Oldest        *Program         `json:"oldest,omitempty"` // Earliest InstallDate (nil if no program has one)
+	Newest        *Program         `json:"newest,omitempty"` // Latest InstallDate
+}
+
+// computeStats works out the totals, top programs and date range
+func computeStats(programs []Program) inventoryStats {
+	stats := inventoryStats{TotalPrograms: len(programs)}
+
+	// Step 1: Total size and the date range
+	for i, program := range programs {
+		stats.TotalSizeKB += program.SizeKB
+
+		if program.InstallDate == nil {
+			continue
+		}
+		if stats.Oldest == nil || program.InstallDate.Before(*stats.Oldest.InstallDate) {
+			stats.Oldest = &programs[i]
+		}
+		if stats.Newest == nil || program.InstallDate.After(*stats.Newest.InstallDate) {
+			stats.Newest = &programs[i]
+		}
+	}
+
+	// Step 2: The largest programs (only those that report a size)
+	var sized []Program
+	for _, program := range programs {
+		if program.SizeKB > 0 {
+			sized = append(sized, program)
+		}
+	}
+	sortPrograms(sized, "size")
+	if len(sized) > statsTopCount {
+		sized = sized[:statsTopCount]
+	}
+	stats.Largest = sized
+	if stats.Largest == nil {
+		stats.Largest = []Program{}
+	}
+
+	// Step 3: Programs per publisher, most programs first
+	counts := make(map[string]int)
+	for _, program := range programs {
+		publisher := program.Publisher
+		if publisher == "" {
+			publisher = "(unknown)"
+		}
+		counts[publisher]++
+	}
+	stats.Publishers = []publisherCount{}
+	for publisher, count := range counts {
+		stats.Publishers = append(stats.Publishers, publisherCount{Publisher: publisher, Count: count})
+	}
+	sort.Slice(stats.Publishers, func(i, j int) bool {
+		if stats.Publishers[i].Count != stats.Publishers[j].Count {
+			return stats.Publishers[i].Count > stats.Publishers[j].Count
+		}
+		return strings.ToLower(stats.Publishers[i].Publisher) < strings.ToLower(stats.Publishers[j].Publisher)
+	})
+
+	return stats
+}
+
+// displayStats prints the stats in a human-readable layout
+func displayStats(stats inventoryStats) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("Programs: %d, using %s in total\n", stats.TotalPrograms, formatSize(stats.TotalSizeKB, false))
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	fmt.Printf("Largest programs:\n")
+	for i, program := range stats.Largest {
+		fmt.Printf("  %2d. %-10s %s\n", i+1, formatSize(program.SizeKB, false), programLabel(program))
+	}
+
+	fmt.Printf("\nPrograms per publisher:\n")
+	for _, publisher := range stats.Publishers {
+		fmt.Printf("  %4d  %s\n", publisher.Count, publisher.Publisher)
+	}
+
+	fmt.Printf("\nInstall dates:\n")
+	if stats.Oldest == nil {
+		fmt.Printf("  No programs record an install date\n")
+		return
+	}
+	fmt.Printf("  Oldest: %s  %s\n", installedLabel(*stats.Oldest), programLabel(*stats.Oldest))
+	fmt.Printf("  Newest: %s  %s\n", installedLabel(*stats.Newest), programLabel(*stats.Newest))
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	// Add the --json flag for dashboards and scripts
+	statsCmd.Flags().Bool("json", false, "Print the stats as JSON")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(statsCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// templateData is what a --template sees: .Programs for the list, and .Meta
+// for the scan details (.Meta.Hostname, .Meta.Timestamp, .Meta.TotalCount, ...)
+type templateData struct {
+	Programs []Program
+	Meta     ScanResult
+}
+
+// templateFuncs are the helpers available in --template, on top of Go's built-ins
+var templateFuncs = template.FuncMap{
+	"size":  func(sizeKB uint64) string { return formatSize(sizeKB, false) }, // {{size .SizeKB}} -> "312.5 MB"
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+}
+
+// parseOutputTemplate reads --template or --template-file
+// Parsing happens before the scan, so a broken template fails fast
+// Returns nil when neither flag was given
+func parseOutputTemplate(text, filename string) (*template.Template, error) {
+	if text != "" && filename != "" {
+		return nil, fmt.Errorf("--template and --template-file can't be used together")
+	}
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %v", err)
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// saveWithTemplate writes the programs through the user's template
+// to a file, or to stdout when filename is "" or "-"
+func saveWithTemplate(programs []Program, filename string, opts outputOptions) error {
+	if filename == "" {
+		filename = stdoutName
+	}
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close() // Throws the temporary file away unless it was committed
+
+	data := templateData{Programs: programs, Meta: newScanResult(programs, opts.Label)}
+	err = opts.Template.Execute(file, data)
+	if err != nil {
+		return fmt.Errorf("failed to run template: %v", err)
+	}
+	err = file.Commit()
+	if err != nil {
+		return err
+	}
+
+	if !opts.Quiet && filename != stdoutName {
+		fmt.Printf("\nResults saved with template: %s\n", filename)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// uninstallCmd represents the uninstall command
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall <name>",
+	Short: "Silently uninstall a program by name",
+	Long: `Find an installed program by its exact name and run its quiet
+uninstall command (the QuietUninstallString registry value).
+
+The name must match the whole program name (case-insensitive), so a typo
+can't remove the wrong program. Use "winclone search" to find the exact name.
+Only programs that register a quiet uninstall command can be removed this way;
+for the others the normal uninstall command is printed so you can run it
+yourself.
+
+The command is shown first and only run after you answer "y" to the prompt.
+--yes runs it without asking, for scripts; without a console to ask on and
+without --yes nothing is run. --dry-run only shows the command.
+Most uninstallers need an elevated (Administrator) prompt.
+
+Examples:
+  winclone uninstall "7-Zip 23.01 (x64)" --dry-run   # Show what would run
+  winclone uninstall "7-Zip 23.01 (x64)"             # Show it, then ask before running
+  winclone uninstall "7-Zip 23.01 (x64)" --yes       # Uninstall it without asking`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone uninstall <name>"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		name := args[0]
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		fmt.Fprintln(os.Stderr, "WinClone - Looking up program...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		// Step 1: Find exactly one program with that name
+		matches := filterByExactName(programs, name)
+		if len(matches) == 0 {
+			return fmt.Errorf("no program is named %q (try \"winclone search\" to find the exact name)", name)
+		}
+		if len(matches) > 1 {
+			fmt.Fprintf(os.Stderr, "%d programs are named %q:\n", len(matches), name)
+			for _, program := range matches {
+				fmt.Fprintf(os.Stderr, "  %s (%s)\n", programLabel(program), program.Source)
+			}
+			return fmt.Errorf("more than one program matches %q, uninstall it from Settings instead", name)
+		}
+		program := matches[0]
+
+		// Step 2: Only the quiet command is run, so nothing waits for a click
+		if program.QuietUninstallString == "" {
+			if program.UninstallString != "" {
+				fmt.Fprintf(os.Stderr, "Uninstall command: %s\n", program.UninstallString)
+			}
+			return fmt.Errorf("%s has no quiet uninstall command", programLabel(program))
+		}
+
+		// Step 3: Show the command, and only run it once the user has agreed
+		fmt.Printf("Would uninstall %s by running:\n  %s\n", programLabel(program), program.QuietUninstallString)
+		if dryRun {
+			return nil
+		}
+		if !yes {
+			if !isConsole(os.Stdin) {
+				fmt.Fprintln(os.Stderr, "Nothing was uninstalled; add --yes to run it without a prompt")
+				return nil
+			}
+			if !confirm(os.Stdin, os.Stdout, "Uninstall it? [y/N] ") {
+				fmt.Println("Nothing was uninstalled.")
+				return nil
+			}
+		}
+
+		// Step 4: Run it
+		fmt.Printf("Uninstalling %s...\n", programLabel(program))
+		err = runUninstallCommand(program.QuietUninstallString)
+		if err != nil {
+			return fmt.Errorf("uninstall failed: %v", err)
+		}
+		fmt.Printf("Uninstalled %s\n", programLabel(program))
+
+		return nil
+	},
+}
+
+// confirm asks a yes/no question and reads the answer from r
+// Only "y" or "yes" (any case) count as yes; anything else, including an empty
+// line or end of input, is no
+func confirm(r io.Reader, w io.Writer, question string) bool {
+	fmt.Fprint(w, question)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// runUninstallCommand runs a command line from the registry and waits for it
+// Uninstall strings are whole command lines with their own quoting, e.g.
+// "C:\Program Files\App\uninst.exe" /S or MsiExec.exe /X{GUID} /qn, so they're
+// handed to cmd.exe as-is instead of being split into arguments
+func runUninstallCommand(commandLine string) error {
+	shell := os.Getenv("ComSpec")
+	if shell == "" {
+		shell = "cmd.exe"
+	}
+
+	command := exec.Command(shell)
+	// CmdLine replaces the whole command line, including the program name
+	// The outer quotes are stripped by cmd /S, leaving the original line untouched
+	command.SysProcAttr = &syscall.SysProcAttr{CmdLine: syscall.EscapeArg(shell) + ` /S /C "` + commandLine + `"`}
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+
+	// Add the --dry-run flag to preview the command without running it
+	uninstallCmd.Flags().Bool("dry-run", false, "Print the uninstall command instead of running it")
+
+	// Add the --yes flag to run the command without the confirmation prompt
+	uninstallCmd.Flags().BoolP("yes", "y", false, "Uninstall without asking for confirmation")
+	uninstallCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(uninstallCmd)
+}
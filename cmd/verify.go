@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that each program's install path still exists",
+	Long: `Scan the installed programs and check every install path on disk.
+
+Uninstalling a program by deleting its folder leaves its registry entry
+behind, so the path recorded in the registry can point at nothing. verify
+lists those broken entries. Programs without an install path are not checked.
+
+Examples:
+  winclone verify                # Print the programs with missing paths
+  winclone verify --json         # Print a JSON report for scripts
+  winclone verify --exit-code    # Exit with code 1 if any path is missing`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone verify"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+
+		// Status lines go to stderr so they never mix with the JSON on stdout
+		fmt.Fprintln(os.Stderr, "WinClone - Verifying install paths...")
+		fmt.Fprintln(os.Stderr, "==========================================")
+
+		programs, err := scanVisiblePrograms(newScanner(runtime.NumCPU()), includeUpdates)
+		if err != nil {
+			return err
+		}
+		sortPrograms(programs, "name")
+
+		report := verifyPaths(programs)
+
+		if asJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+			err = encoder.Encode(report)
+			if err != nil {
+				return fmt.Errorf("failed to encode JSON: %v", err)
+			}
+		} else {
+			displayVerifyReport(report)
+		}
+
+		// Fail the pipeline if anything is broken
+		if exitCode && len(report.Missing) > 0 {
+			os.Exit(1)
+		}
+
+		return nil
+	},
+}
+
+// verifyReport is the result of checking install paths
+type verifyReport struct {
+	Checked int       `json:"checked"` // Programs that had a path to check
+	Missing []Program `json:"missing"` // Programs whose path doesn't exist
+}
+
+// verifyPaths checks every non-empty install path with os.Stat
+// Only "does not exist" counts as missing; a path we aren't allowed to look
+// at is still there, so it isn't reported as broken
+func verifyPaths(programs []Program) verifyReport {
+	report := verifyReport{Missing: []Program{}}
+
+	for _, program := range programs {
+		// Some registry values are quoted, e.g. "C:\Program Files\App"
+		path := strings.Trim(strings.TrimSpace(program.Path), `"`)
+		if path == "" {
+			continue
+		}
+		report.Checked++
+
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			report.Missing = append(report.Missing, program)
+		}
+	}
+
+	return report
+}
+
+// displayVerifyReport prints the programs with missing install paths
+func displayVerifyReport(report verifyReport) {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("Checked %d install paths, %d missing\n", report.Checked, len(report.Missing))
+	fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+	for _, program := range report.Missing {
+		fmt.Printf("  %s\n", programLabel(program))
+		fmt.Printf("    Path: %s\n", program.Path)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	// Add the --json flag for scripts
+	verifyCmd.Flags().Bool("json", false, "Print the report as JSON")
+
+	// Add the --exit-code flag for CI and compliance checks
+	verifyCmd.Flags().Bool("exit-code", false, "Exit with code 1 if any install path is missing")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(verifyCmd)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Report program installs and removals as they happen",
+	Long: `Take a baseline scan, then check the registry every --interval seconds
+and print one JSON object per line for each change:
+
+  {"event":"installed","time":"...","program":{...}}
+  {"event":"removed","time":"...","program":{...}}
+  {"event":"updated","time":"...","program":{...},"previous":{...}}
+
+Each check first reads the last-write time of the Uninstall keys, which is
+cheap; the full rescan only runs when one of them has changed. After reporting,
+the new scan becomes the baseline, so each change is printed once.
+
+The output is meant for log shippers and scripts; status messages go to stderr.
+Press Ctrl+C to stop.
+
+Examples:
+  winclone watch                            # Check every 30 seconds
+  winclone watch --interval 5               # Check every 5 seconds
+  winclone watch >> software-changes.jsonl  # Keep a change log`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// This function runs when the user types "winclone watch"
+		includeUpdates, _ := cmd.Flags().GetBool("include-updates")
+		seconds, _ := cmd.Flags().GetInt("interval")
+		if seconds < 1 {
+			return fmt.Errorf("--interval must be at least 1 second, got %d", seconds)
+		}
+		interval := time.Duration(seconds) * time.Second
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		// Step 1: Take the baseline scan
+		scanner := newScanner(runtime.NumCPU())
+		lastChange, err := scanner.lastChange()
+		if err != nil {
+			return fmt.Errorf("failed to read the registry: %v", err)
+		}
+		baseline, err := scanVisiblePrograms(scanner, includeUpdates)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "WinClone - Watching %d programs, checking every %s (Ctrl+C to stop)\n", len(baseline), interval)
+
+		encoder := json.NewEncoder(os.Stdout)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Step 2: Wait for the next check, or stop on Ctrl+C
+			select {
+			case <-ctx.Done():
+				fmt.Fprintln(os.Stderr, "Stopped watching.")
+				return nil
+			case <-ticker.C:
+			}
+
+			// Step 3: Only rescan when an Uninstall key was written to
+			changed, err := scanner.lastChange()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Could not check the registry: %v\n", err)
+				continue
+			}
+			if !changed.After(lastChange) {
+				continue
+			}
+
+			programs, err := scanVisiblePrograms(scanner, includeUpdates)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				continue // Keep the old baseline and try again next time
+			}
+			lastChange = changed
+
+			// Step 4: Print the differences and make this scan the new baseline
+			err = writeWatchEvents(encoder, diffPrograms(baseline, programs), time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to write events: %v", err)
+			}
+			baseline = programs
+		}
+	},
+}
+
+// watchEvent is one line of "winclone watch" output
+type watchEvent struct {
+	Event    string    `json:"event"`              // "installed", "removed" or "updated"
+	Time     time.Time `json:"time"`               // When the change was noticed
+	Program  Program   `json:"program"`            // The program as it is now (or was, for "removed")
+	Previous *Program  `json:"previous,omitempty"` // The program before an update
+}
+
+// writeWatchEvents writes one JSON line per change in diff
+func writeWatchEvents(encoder *json.Encoder, diff programDiff, now time.Time) error {
+	var events []watchEvent
+	for _, program := range diff.Added {
+		events = append(events, watchEvent{Event: "installed", Time: now, Program: program})
+	}
+	for _, program := range diff.Removed {
+		events = append(events, watchEvent{Event: "removed", Time: now, Program: program})
+	}
+	for _, change := range diff.Changed {
+		previous := change.Old
+		events = append(events, watchEvent{Event: "updated", Time: now, Program: change.New, Previous: &previous})
+	}
+
+	for _, event := range events {
+		err := encoder.Encode(event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearScreen is the ANSI sequence that moves the cursor home and clears the console
+const clearScreen = "\033[H\033[2J"
+
+// watchScan rescans every interval and shows the current count plus what
+// appeared or disappeared since the previous cycle, until Ctrl+C is pressed
+// System components, duplicates and Windows updates are left out, as in the normal list
+func watchScan(scanner *Scanner, interval time.Duration, includeUpdates bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	enableVirtualTerminal()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var previous []Program
+	for cycle := 1; ; cycle++ {
+		// Step 1: Scan
+		programs, err := scanVisiblePrograms(scanner, includeUpdates)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Redraw the screen with the count and the changes
+		fmt.Print(clearScreen)
+		fmt.Printf("WinClone - Watching installed programs every %s (Ctrl+C to stop)\n", interval)
+		fmt.Printf("%s\n", strings.Repeat("=", 50))
+		fmt.Printf("%s  %d programs installed\n", time.Now().Format("2006-01-02 15:04:05"), len(programs))
+		fmt.Printf("%s\n\n", strings.Repeat("=", 50))
+
+		if cycle == 1 {
+			fmt.Println("First scan - changes will show from the next cycle.")
+		} else {
+			diff := diffPrograms(previous, programs)
+			if diff.isEmpty() {
+				fmt.Println("No changes since the last cycle.")
+			} else {
+				displayDiff(diff)
+			}
+		}
+		previous = programs
+
+		// Step 3: Wait for the next tick, or stop on Ctrl+C
+		select {
+		case <-ctx.Done():
+			fmt.Println("\nStopped watching.")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// enableVirtualTerminal turns on ANSI escape handling in the Windows console,
+// so clearScreen works in the classic console as well as Windows Terminal
+// Failure is ignored: the worst case is some stray characters on screen
+func enableVirtualTerminal() {
+	handle := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(handle, &mode) != nil {
+		return // Not a console, e.g. output is redirected to a file
+	}
+	windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	// Add the --interval flag for how often to check the registry
+	watchCmd.Flags().Int("interval", 30, "Seconds between registry checks")
+
+	// Add the --include-updates flag to keep patch entries like "Security Update for ..."
+	addIncludeUpdatesFlag(watchCmd)
+}